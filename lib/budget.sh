@@ -0,0 +1,80 @@
+#!/usr/bin/env bash
+# budget.sh — Spend budgets for workmode
+# Limits come from budget_daily_usd / budget_monthly_usd per trigger and in
+# [general]; spend is the cost_usd workmode-run records in history.jsonl.
+# Requires config.sh, HISTORY_FILE and STATE_DIR.
+
+# Sum the cost of sessions started in a period
+# Usage: budget_spent <trigger|""> <YYYY-MM-DD|YYYY-MM>
+budget_spent() {
+    local trigger="$1" period="$2"
+    [[ -f "$HISTORY_FILE" ]] || { echo 0; return; }
+    # history is append-only; the last line per id carries the final cost
+    awk -v trigger="$trigger" -v period="$period" '
+        function field(name,   re) {
+            re = "\"" name "\":\"[^\"]*\""
+            if (!match($0, re)) return ""
+            return substr($0, RSTART + length(name) + 4, RLENGTH - length(name) - 5)
+        }
+        {
+            id = field("id")
+            if (id == "") next
+            if (trigger != "" && field("trigger") != trigger) next
+            if (index(field("started"), period) != 1) next
+            cost[id] = 0
+            if (match($0, /"cost_usd":[0-9.eE+-]+/)) cost[id] = substr($0, RSTART + 11, RLENGTH - 11)
+        }
+        END {
+            for (id in cost) total += cost[id]
+            printf "%.4f\n", total
+        }
+    ' "$HISTORY_FILE"
+}
+
+# Print a trigger's limit for a period, falling back to nothing
+# Usage: budget_limit <trigger|""> <daily|monthly>
+budget_limit() {
+    local trigger="$1" period="$2"
+    if [[ -n "$trigger" ]]; then
+        config_trigger_field "$trigger" "budget_${period}_usd" 2>/dev/null || true
+    else
+        config_general "budget_${period}_usd" 2>/dev/null || true
+    fi
+}
+
+# Print why a trigger is over budget and return 0, or return 1 if it may run.
+# Checks the trigger's own daily/monthly limits, then the global ones.
+budget_exceeded() {
+    local trigger="$1" scope period limit spent prefix
+    for scope in trigger global; do
+        for period in daily monthly; do
+            if [[ "$scope" == "trigger" ]]; then
+                limit="$(budget_limit "$trigger" "$period")"
+            else
+                limit="$(budget_limit "" "$period")"
+            fi
+            [[ -n "$limit" ]] || continue
+            prefix="$(date +%Y-%m-%d)"
+            [[ "$period" == "monthly" ]] && prefix="$(date +%Y-%m)"
+            spent="$(budget_spent "$([[ "$scope" == "trigger" ]] && echo "$trigger")" "$prefix")"
+            if awk -v s="$spent" -v l="$limit" 'BEGIN { exit !(l > 0 && s >= l) }'; then
+                printf '%s %s budget $%.2f reached ($%.2f spent)\n' "$scope" "$period" "$limit" "$spent"
+                return 0
+            fi
+        done
+    done
+    return 1
+}
+
+# File that lets the next run of a trigger start despite its budget
+budget_override_file() {
+    echo "$STATE_DIR/budget/$1.override"
+}
+
+# Consume a pending one-time override; returns 1 if there was none
+budget_take_override() {
+    local file
+    file="$(budget_override_file "$1")"
+    [[ -f "$file" ]] || return 1
+    rm -f "$file"
+}
@@ -0,0 +1,162 @@
+#!/usr/bin/env bash
+# lib/cmd/budget.sh — Spend budget commands
+
+dispatch_budget() {
+    local subcmd="${1:-list}"
+    shift || true
+
+    case "$subcmd" in
+        list)     cmd_budget_list "$@" ;;
+        set)      cmd_budget_set "$@" ;;
+        override) cmd_budget_override "$@" ;;
+        help|--help|-h) usage_budget ;;
+        *)        die "Unknown budget command: $subcmd" ;;
+    esac
+}
+
+usage_budget() {
+    cat <<EOF
+Usage: workmode budget <command> [options]
+
+Commands:
+  list [--json]                                        Show spend against limits
+  set <trigger>|--global [--daily <usd>] [--monthly <usd>]
+                                                       Set spend limits (0 = none)
+  override <trigger>                                   Let the next run start despite its budget
+
+Budgets are budget_daily_usd / budget_monthly_usd per trigger and in
+[general]. A trigger over its own or the global budget is budget-paused:
+its runs are skipped until the period rolls over.
+
+EOF
+    exit 0
+}
+
+cmd_budget_list() {
+    parse_global_flags "$@"
+    $SHOW_HELP && usage_budget
+
+    local today month
+    today="$(date +%Y-%m-%d)"
+    month="$(date +%Y-%m)"
+
+    if [[ "$OUTPUT_FORMAT" != "json" ]]; then
+        printf "  %-14s %-20s %-20s %s\n" "TRIGGER" "TODAY" "THIS MONTH" "STATE"
+        echo "  ────────────── ──────────────────── ──────────────────── ──────────────────────"
+    fi
+    _budget_row "" "$today" "$month"
+    local name
+    for name in $(config_list_triggers); do
+        _budget_row "$name" "$today" "$month"
+    done
+}
+
+cmd_budget_set() {
+    local target="" daily="" monthly=""
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
+            --global)  target="--global"; shift ;;
+            --daily)   daily="${2:-}"; shift 2 || shift ;;
+            --monthly) monthly="${2:-}"; shift 2 || shift ;;
+            -h|--help) usage_budget ;;
+            *)         target="$1"; shift ;;
+        esac
+    done
+    [[ -z "$target" || -z "$daily$monthly" ]] && {
+        code=$EX_USAGE die "Usage: workmode budget set <trigger>|--global [--daily <usd>] [--monthly <usd>]"
+    }
+
+    local trigger="" scope="global"
+    if [[ "$target" != "--global" ]]; then
+        trigger="$target"
+        scope="'$trigger'"
+        config_trigger_field "$trigger" "name" &>/dev/null || {
+            code=$EX_NOT_FOUND die "Trigger '$trigger' not found"
+        }
+    fi
+
+    local period value
+    for period in daily monthly; do
+        value="$daily"
+        [[ "$period" == "monthly" ]] && value="$monthly"
+        [[ -z "$value" ]] && continue
+        [[ "$value" =~ ^[0-9]+(\.[0-9]+)?$ ]] || {
+            code=$EX_USAGE die "Invalid $period budget '$value' (expected an amount in USD, e.g. 5 or 2.50)"
+        }
+        config_set_field "$trigger" "budget_${period}_usd" "$value" || {
+            code=$EX_CONFIG die "Could not update $WORKMODE_CONFIG (no [general] section?)"
+        }
+        printf 'Set %s %s budget to $%.2f\n' "$scope" "$period" "$value"
+    done
+}
+
+cmd_budget_override() {
+    local trigger="${1:-}"
+    [[ -z "$trigger" ]] && { code=$EX_USAGE die "Usage: workmode budget override <trigger>"; }
+    config_trigger_field "$trigger" "name" &>/dev/null || {
+        code=$EX_NOT_FOUND die "Trigger '$trigger' not found"
+    }
+
+    local file
+    file="$(budget_override_file "$trigger")"
+    mkdir -p "$(dirname "$file")"
+    touch "$file"
+    echo "The next run of '$trigger' will start despite its budget."
+}
+
+# --- Helpers ---
+
+# Print one list row (or JSON object); an empty trigger is the global budget
+_budget_row() {
+    local trigger="$1" today="$2" month="$3"
+    local daily monthly spent_day spent_month reason="" override=false
+    daily="$(budget_limit "$trigger" daily)"
+    monthly="$(budget_limit "$trigger" monthly)"
+    spent_day="$(budget_spent "$trigger" "$today")"
+    spent_month="$(budget_spent "$trigger" "$month")"
+    if [[ -n "$trigger" ]]; then
+        reason="$(budget_exceeded "$trigger" || true)"
+        [[ -f "$(budget_override_file "$trigger")" ]] && override=true
+    fi
+
+    if [[ "$OUTPUT_FORMAT" == "json" ]]; then
+        local fields
+        if [[ -n "$trigger" ]]; then
+            fields="$(json_field "scope" "trigger"),$(json_field "trigger" "$trigger")"
+        else
+            fields="$(json_field "scope" "global")"
+        fi
+        fields+=",$(json_field_num "spent_today" "$spent_day"),$(json_field_num "spent_month" "$spent_month")"
+        [[ -n "$daily" ]] && fields+=",$(json_field_num "budget_daily_usd" "$daily")"
+        [[ -n "$monthly" ]] && fields+=",$(json_field_num "budget_monthly_usd" "$monthly")"
+        if [[ -n "$trigger" ]]; then
+            fields+=",$(json_field_bool "paused" "$([[ -n "$reason" ]] && echo true || echo false)")"
+            [[ -n "$reason" ]] && fields+=",$(json_field "reason" "$reason")"
+            fields+=",$(json_field_bool "override" "$override")"
+        fi
+        json_object "$fields"
+        echo
+        return
+    fi
+
+    local state="ok"
+    if [[ -n "$reason" ]]; then
+        state="budget-paused"
+        $override && state+=" (override pending)"
+    fi
+    [[ -z "$trigger" ]] && state=""
+    printf "  %-14s %-20s %-20s %s\n" "${trigger:-(global)}" \
+        "$(_budget_amount "$spent_day" "$daily")" \
+        "$(_budget_amount "$spent_month" "$monthly")" \
+        "$state"
+}
+
+# "$1.20 / $5.00", or just the spend when there is no limit
+_budget_amount() {
+    local spent="$1" limit="$2"
+    if [[ -n "$limit" ]]; then
+        printf '$%.2f / $%.2f' "$spent" "$limit"
+    else
+        printf '$%.2f' "$spent"
+    fi
+}
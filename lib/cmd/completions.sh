@@ -23,9 +23,11 @@ _workmode() {
     local cur prev words cword
     _init_completion || return
 
-    local top_commands="on off status trigger session config install uninstall tui completions help version"
+    local top_commands="on off status daemon trigger session budget history config install uninstall tui completions help version"
     local trigger_commands="list show run enable disable"
-    local session_commands="list logs tail resume stop kill"
+    local session_commands="list logs tail resume stop kill cancel priority pin unpin export revert approve deny"
+    local budget_commands="list set override"
+    local history_commands="compact prune"
     local config_commands="show edit validate apply path"
     local completions_shells="bash zsh fish"
 
@@ -41,6 +43,12 @@ _workmode() {
                 session)
                     COMPREPLY=( $(compgen -W "$session_commands" -- "$cur") )
                     ;;
+                budget)
+                    COMPREPLY=( $(compgen -W "$budget_commands" -- "$cur") )
+                    ;;
+                history)
+                    COMPREPLY=( $(compgen -W "$history_commands" -- "$cur") )
+                    ;;
                 config)
                     COMPREPLY=( $(compgen -W "$config_commands" -- "$cur") )
                     ;;
@@ -69,14 +77,37 @@ _workmode() {
                     ;;
                 session)
                     case "${words[2]}" in
-                        logs|tail|resume|stop|kill)
+                        logs|tail|resume|stop|kill|cancel|priority|pin|unpin|export|revert|approve|deny)
                             # Complete session IDs
                             local sessions
                             sessions="$(workmode session list --json 2>/dev/null | grep -oP '"short"\s*:\s*"[^"]*"' | grep -oP '"[^"]*"$' | tr -d '"')"
                             COMPREPLY=( $(compgen -W "$sessions" -- "$cur") )
                             ;;
                         list)
-                            COMPREPLY=( $(compgen -W "--json --running --stuck --completed --all" -- "$cur") )
+                            COMPREPLY=( $(compgen -W "--json --running --queued --stuck --completed --all" -- "$cur") )
+                            ;;
+                    esac
+                    ;;
+                budget)
+                    case "${words[2]}" in
+                        set|override)
+                            local triggers
+                            triggers="$(workmode trigger list --json 2>/dev/null | grep -oP '"name"\s*:\s*"[^"]*"' | grep -oP '"[^"]*"$' | tr -d '"')"
+                            [[ "${words[2]}" == "set" ]] && triggers+=" --global"
+                            COMPREPLY=( $(compgen -W "$triggers" -- "$cur") )
+                            ;;
+                        list)
+                            COMPREPLY=( $(compgen -W "--json" -- "$cur") )
+                            ;;
+                    esac
+                    ;;
+                history)
+                    case "${words[2]}" in
+                        prune)
+                            COMPREPLY=( $(compgen -W "--days --per-trigger --dry-run --quiet --json" -- "$cur") )
+                            ;;
+                        compact)
+                            COMPREPLY=( $(compgen -W "--json" -- "$cur") )
                             ;;
                     esac
                     ;;
@@ -89,6 +120,13 @@ _workmode() {
                     ;;
             esac
             ;;
+        *)
+            if [[ "${words[1]}" == "budget" && "${words[2]}" == "set" ]]; then
+                COMPREPLY=( $(compgen -W "--daily --monthly" -- "$cur") )
+            elif [[ "${words[1]}" == "history" && "${words[2]}" == "prune" ]]; then
+                COMPREPLY=( $(compgen -W "--days --per-trigger --dry-run --quiet --json" -- "$cur") )
+            fi
+            ;;
     esac
 }
 
@@ -101,14 +139,17 @@ _completions_zsh() {
 #compdef workmode
 
 _workmode() {
-    local -a top_commands trigger_commands session_commands config_commands
+    local -a top_commands trigger_commands session_commands budget_commands history_commands config_commands
 
     top_commands=(
         'on:Activate all triggers'
         'off:Deactivate all triggers'
         'status:Show current state and summary'
+        'daemon:Fire triggers without systemd'
         'trigger:Manage triggers'
         'session:Manage sessions'
+        'budget:Spend budgets'
+        'history:Compact and prune session history'
         'config:Manage configuration'
         'install:Install systemd units'
         'uninstall:Remove systemd units'
@@ -133,6 +174,25 @@ _workmode() {
         'resume:Resume interactive session'
         'stop:Graceful stop'
         'kill:Force kill'
+        'cancel:Remove a queued run'
+        'priority:Reprioritize a queued run'
+        'pin:Keep a session through pruning'
+        'unpin:Let a session be pruned again'
+        'export:Write the transcript to a file'
+        'revert:Undo changes to the git working dir'
+        'approve:Allow refused tool calls and resume'
+        'deny:Leave refused tool calls denied'
+    )
+
+    budget_commands=(
+        'list:Show spend against limits'
+        'set:Set spend limits'
+        'override:Let the next run start despite its budget'
+    )
+
+    history_commands=(
+        'compact:Rewrite history with one line per session'
+        'prune:Apply retention and delete old logs'
     )
 
     config_commands=(
@@ -165,7 +225,7 @@ _workmode() {
                 _describe 'session command' session_commands
             elif (( CURRENT == 4 )); then
                 case "$words[3]" in
-                    logs|tail|resume|stop|kill)
+                    logs|tail|resume|stop|kill|cancel|priority|pin|unpin|export|revert|approve|deny)
                         local -a sessions
                         sessions=(${(f)"$(workmode session list --json 2>/dev/null | grep -oP '"short"\s*:\s*"[^"]*"' | grep -oP '"[^"]*"$' | tr -d '"')"})
                         _describe 'session id' sessions
@@ -174,6 +234,7 @@ _workmode() {
                         _arguments \
                             '--json[Output as JSON]' \
                             '--running[Show running only]' \
+                            '--queued[Show queued only]' \
                             '--stuck[Show stuck only]' \
                             '--completed[Show completed only]' \
                             '--all[Show all sessions]'
@@ -181,6 +242,39 @@ _workmode() {
                 esac
             fi
             ;;
+        budget)
+            if (( CURRENT == 3 )); then
+                _describe 'budget command' budget_commands
+            elif (( CURRENT == 4 )); then
+                case "$words[3]" in
+                    set|override)
+                        local -a triggers
+                        triggers=(${(f)"$(workmode trigger list --json 2>/dev/null | grep -oP '"name"\s*:\s*"[^"]*"' | grep -oP '"[^"]*"$' | tr -d '"')"})
+                        [[ "$words[3]" == set ]] && triggers+=(--global)
+                        _describe 'trigger name' triggers
+                        ;;
+                    list)
+                        _arguments '--json[Output as JSON]'
+                        ;;
+                esac
+            elif [[ "$words[3]" == set ]]; then
+                _arguments '--daily[Daily limit in USD]:usd' '--monthly[Monthly limit in USD]:usd'
+            fi
+            ;;
+        history)
+            if (( CURRENT == 3 )); then
+                _describe 'history command' history_commands
+            elif [[ "$words[3]" == prune ]]; then
+                _arguments \
+                    '--days[Keep sessions from the last n days]:days' \
+                    '--per-trigger[Keep the n newest sessions per trigger]:count' \
+                    '--dry-run[Show what would be removed]' \
+                    '--quiet[Only print errors]' \
+                    '--json[Output as JSON]'
+            elif [[ "$words[3]" == compact ]]; then
+                _arguments '--json[Output as JSON]'
+            fi
+            ;;
         config)
             if (( CURRENT == 3 )); then
                 _describe 'config command' config_commands
@@ -223,8 +317,11 @@ complete -c workmode -f
 complete -c workmode -n '__fish_use_subcommand' -a 'on' -d 'Activate all triggers'
 complete -c workmode -n '__fish_use_subcommand' -a 'off' -d 'Deactivate all triggers'
 complete -c workmode -n '__fish_use_subcommand' -a 'status' -d 'Show current state'
+complete -c workmode -n '__fish_use_subcommand' -a 'daemon' -d 'Fire triggers without systemd'
 complete -c workmode -n '__fish_use_subcommand' -a 'trigger' -d 'Manage triggers'
 complete -c workmode -n '__fish_use_subcommand' -a 'session' -d 'Manage sessions'
+complete -c workmode -n '__fish_use_subcommand' -a 'budget' -d 'Spend budgets'
+complete -c workmode -n '__fish_use_subcommand' -a 'history' -d 'Compact and prune history'
 complete -c workmode -n '__fish_use_subcommand' -a 'config' -d 'Manage configuration'
 complete -c workmode -n '__fish_use_subcommand' -a 'install' -d 'Install systemd units'
 complete -c workmode -n '__fish_use_subcommand' -a 'uninstall' -d 'Remove systemd units'
@@ -244,12 +341,38 @@ complete -c workmode -n '__fish_seen_subcommand_from trigger; and not __fish_see
 complete -c workmode -n '__fish_seen_subcommand_from trigger; and not __fish_seen_subcommand_from list show run enable disable' -a 'disable' -d 'Disable trigger'
 
 # session subcommands
-complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill' -a 'list' -d 'List sessions'
-complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill' -a 'logs' -d 'Show session output'
-complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill' -a 'tail' -d 'Follow session'
-complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill' -a 'resume' -d 'Resume session'
-complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill' -a 'stop' -d 'Stop session'
-complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill' -a 'kill' -d 'Kill session'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'list' -d 'List sessions'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'logs' -d 'Show session output'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'tail' -d 'Follow session'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'resume' -d 'Resume session'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'stop' -d 'Stop session'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'kill' -d 'Kill session'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'cancel' -d 'Cancel queued run'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'priority' -d 'Reprioritize queued run'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'pin' -d 'Keep through pruning'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'unpin' -d 'Allow pruning again'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'export' -d 'Write transcript to a file'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'revert' -d 'Undo working dir changes'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'approve' -d 'Allow refused tool calls'
+complete -c workmode -n '__fish_seen_subcommand_from session; and not __fish_seen_subcommand_from list logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a 'deny' -d 'Leave refused calls denied'
+
+# budget subcommands
+complete -c workmode -n '__fish_seen_subcommand_from budget; and not __fish_seen_subcommand_from list set override' -a 'list' -d 'Show spend against limits'
+complete -c workmode -n '__fish_seen_subcommand_from budget; and not __fish_seen_subcommand_from list set override' -a 'set' -d 'Set spend limits'
+complete -c workmode -n '__fish_seen_subcommand_from budget; and not __fish_seen_subcommand_from list set override' -a 'override' -d 'Override budget once'
+complete -c workmode -n '__fish_seen_subcommand_from budget; and __fish_seen_subcommand_from list' -l json -d 'JSON output'
+complete -c workmode -n '__fish_seen_subcommand_from budget; and __fish_seen_subcommand_from set' -l global -d 'Global budget'
+complete -c workmode -n '__fish_seen_subcommand_from budget; and __fish_seen_subcommand_from set' -l daily -r -d 'Daily limit (USD)'
+complete -c workmode -n '__fish_seen_subcommand_from budget; and __fish_seen_subcommand_from set' -l monthly -r -d 'Monthly limit (USD)'
+
+# history subcommands
+complete -c workmode -n '__fish_seen_subcommand_from history; and not __fish_seen_subcommand_from compact prune' -a 'compact' -d 'One line per session'
+complete -c workmode -n '__fish_seen_subcommand_from history; and not __fish_seen_subcommand_from compact prune' -a 'prune' -d 'Apply retention'
+complete -c workmode -n '__fish_seen_subcommand_from history; and __fish_seen_subcommand_from compact prune' -l json -d 'JSON output'
+complete -c workmode -n '__fish_seen_subcommand_from history; and __fish_seen_subcommand_from prune' -l days -r -d 'Keep last n days'
+complete -c workmode -n '__fish_seen_subcommand_from history; and __fish_seen_subcommand_from prune' -l per-trigger -r -d 'Keep n per trigger'
+complete -c workmode -n '__fish_seen_subcommand_from history; and __fish_seen_subcommand_from prune' -l dry-run -d 'Show what would be removed'
+complete -c workmode -n '__fish_seen_subcommand_from history; and __fish_seen_subcommand_from prune' -l quiet -d 'Only print errors'
 
 # config subcommands
 complete -c workmode -n '__fish_seen_subcommand_from config; and not __fish_seen_subcommand_from show edit validate apply path' -a 'show' -d 'Show config'
@@ -264,14 +387,16 @@ complete -c workmode -n '__fish_seen_subcommand_from completions' -a 'bash zsh f
 # session list flags
 complete -c workmode -n '__fish_seen_subcommand_from session; and __fish_seen_subcommand_from list' -l json -d 'JSON output'
 complete -c workmode -n '__fish_seen_subcommand_from session; and __fish_seen_subcommand_from list' -l running -d 'Running only'
+complete -c workmode -n '__fish_seen_subcommand_from session; and __fish_seen_subcommand_from list' -l queued -d 'Queued only'
 complete -c workmode -n '__fish_seen_subcommand_from session; and __fish_seen_subcommand_from list' -l stuck -d 'Stuck only'
 complete -c workmode -n '__fish_seen_subcommand_from session; and __fish_seen_subcommand_from list' -l completed -d 'Completed only'
 complete -c workmode -n '__fish_seen_subcommand_from session; and __fish_seen_subcommand_from list' -l all -d 'Show all'
 
 # Dynamic trigger name completion
+complete -c workmode -n '__fish_seen_subcommand_from budget; and __fish_seen_subcommand_from set override' -a '(workmode trigger list --json 2>/dev/null | string match -r \'"name":"[^"]*"\' | string replace -r \'"name":"([^"]*)"\' \'$1\')'
 complete -c workmode -n '__fish_seen_subcommand_from trigger; and __fish_seen_subcommand_from show run enable disable' -a '(workmode trigger list --json 2>/dev/null | string match -r \'"name":"[^"]*"\' | string replace -r \'"name":"([^"]*)"\' \'$1\')'
 
 # Dynamic session ID completion
-complete -c workmode -n '__fish_seen_subcommand_from session; and __fish_seen_subcommand_from logs tail resume stop kill' -a '(workmode session list --json 2>/dev/null | string match -r \'"short":"[^"]*"\' | string replace -r \'"short":"([^"]*)"\' \'$1\')'
+complete -c workmode -n '__fish_seen_subcommand_from session; and __fish_seen_subcommand_from logs tail resume stop kill cancel priority pin unpin export revert approve deny' -a '(workmode session list --json 2>/dev/null | string match -r \'"short":"[^"]*"\' | string replace -r \'"short":"([^"]*)"\' \'$1\')'
 FISH_COMPLETIONS
 }
@@ -45,11 +45,23 @@ cmd_config_show() {
 
     # General section
     echo "General:"
-    local state_dir max_parallel
+    local state_dir max_parallel timeout
     state_dir="$(config_state_dir)"
     max_parallel="$(config_max_parallel)"
+    timeout="$(config_timeout)"
     echo "  state_dir:    $state_dir"
     echo "  max_parallel: $max_parallel"
+    [[ -n "$timeout" ]] && echo "  timeout:      $timeout"
+    local budget_daily budget_monthly
+    budget_daily="$(config_general "budget_daily_usd" 2>/dev/null || true)"
+    budget_monthly="$(config_general "budget_monthly_usd" 2>/dev/null || true)"
+    [[ -n "$budget_daily" ]] && printf '  budget_daily_usd:   $%.2f\n' "$budget_daily"
+    [[ -n "$budget_monthly" ]] && printf '  budget_monthly_usd: $%.2f\n' "$budget_monthly"
+    local retention_days retention_per_trigger
+    retention_days="$(config_retention_days)"
+    retention_per_trigger="$(config_retention_per_trigger)"
+    [[ -n "$retention_days" ]] && echo "  retention_days:        $retention_days"
+    [[ -n "$retention_per_trigger" ]] && echo "  retention_per_trigger: $retention_per_trigger"
     echo ""
 
     # Triggers
@@ -75,6 +87,36 @@ cmd_config_validate() {
     if [[ ! -f "$WORKMODE_CONFIG" ]]; then
         errors+=("Config file not found: $WORKMODE_CONFIG")
     else
+        local default_timeout
+        default_timeout="$(config_timeout)"
+        if [[ -n "$default_timeout" ]] && ! duration_to_seconds "$default_timeout" >/dev/null; then
+            errors+=("General: invalid timeout '$default_timeout' (e.g. '90s', '30m', '1h30m')")
+        fi
+
+        local budget_key budget_value
+        for budget_key in budget_daily_usd budget_monthly_usd; do
+            budget_value="$(config_general "$budget_key" 2>/dev/null || true)"
+            if [[ -n "$budget_value" && ! "$budget_value" =~ ^[0-9]+(\.[0-9]+)?$ ]]; then
+                errors+=("General: $budget_key must be an amount in USD")
+            fi
+        done
+
+        local retention_key retention_value
+        for retention_key in retention_days retention_per_trigger; do
+            retention_value="$(config_general "$retention_key" 2>/dev/null || true)"
+            if [[ -n "$retention_value" && ! "$retention_value" =~ ^[0-9]+$ ]]; then
+                errors+=("General: $retention_key must be a whole number")
+            fi
+        done
+
+        local webhook_listen
+        webhook_listen="$(config_general "webhook_listen" 2>/dev/null || true)"
+        case "$webhook_listen" in
+            ""|unix:*|/*|127.*:*|localhost:*|\[::1\]:*) ;;
+            *:*) warnings+=("General: webhook_listen $webhook_listen can be reached from other machines") ;;
+            *)   errors+=("General: webhook_listen must be host:port or a unix socket path") ;;
+        esac
+
         # Check each trigger has required fields
         for name in $(config_list_triggers); do
             local type
@@ -82,8 +124,8 @@ cmd_config_validate() {
 
             if [[ -z "$type" ]]; then
                 errors+=("Trigger '$name': missing required field 'type'")
-            elif [[ "$type" != "timer" && "$type" != "file" ]]; then
-                errors+=("Trigger '$name': invalid type '$type' (must be 'timer' or 'file')")
+            elif [[ "$type" != "timer" && "$type" != "file" && "$type" != "webhook" ]]; then
+                errors+=("Trigger '$name': invalid type '$type' (must be 'timer', 'file' or 'webhook')")
             fi
 
             # Must have either skill or prompt
@@ -94,6 +136,17 @@ cmd_config_validate() {
                 errors+=("Trigger '$name': must have either 'skill' or 'prompt'")
             fi
 
+            # A {{...}} prompt is a Go template; parse it now rather than
+            # when the trigger fires
+            if [[ "$prompt_text" == *'{{'* ]]; then
+                local template_error
+                if [[ ! -x "$BIN_DIR/workmode-tui" ]]; then
+                    warnings+=("Trigger '$name': prompt template can't be rendered without the Go binary (run 'workmode install' with go available)")
+                elif ! template_error="$(printf '%s' "$prompt_text" | "$BIN_DIR/workmode-tui" prompt --check 2>&1)"; then
+                    errors+=("Trigger '$name': invalid prompt template: ${template_error#error: }")
+                fi
+            fi
+
             # Timer-specific checks
             if [[ "$type" == "timer" ]]; then
                 local interval cron_expr
@@ -117,6 +170,21 @@ cmd_config_validate() {
                 if [[ -z "$pattern" ]]; then
                     warnings+=("Trigger '$name': no 'pattern' set, will match all files")
                 fi
+                local batch_window
+                batch_window="$(config_trigger_field "$name" "batch_window" 2>/dev/null || true)"
+                if [[ -n "$batch_window" && ! "$batch_window" =~ ^[0-9]+$ ]]; then
+                    errors+=("Trigger '$name': batch_window must be a number of seconds")
+                fi
+            fi
+
+            # Webhook-specific checks
+            if [[ "$type" == "webhook" ]]; then
+                if ! config_trigger_field "$name" "secret" &>/dev/null; then
+                    errors+=("Trigger '$name': webhook trigger needs a 'secret'")
+                fi
+                if [[ ! -x "$BIN_DIR/workmode-tui" ]]; then
+                    warnings+=("Trigger '$name': webhook triggers are served by the Go binary (run 'workmode install' with go available)")
+                fi
             fi
 
             # Working dir check
@@ -133,12 +201,88 @@ cmd_config_validate() {
                 errors+=("Trigger '$name': invalid permissions '$permissions' (must be 'default', 'skip', or 'readonly')")
             fi
 
+            # Priority check
+            local priority
+            priority="$(config_trigger_field "$name" "priority" 2>/dev/null || true)"
+            if [[ -n "$priority" && ! "$priority" =~ ^-?[0-9]+$ ]]; then
+                errors+=("Trigger '$name': priority must be an integer")
+            fi
+
+            # Concurrency check
+            local concurrency
+            concurrency="$(config_trigger_field "$name" "concurrency" 2>/dev/null || true)"
+            if [[ -n "$concurrency" && "$concurrency" != "skip" && "$concurrency" != "queue_one" && "$concurrency" != "replace" ]]; then
+                errors+=("Trigger '$name': invalid concurrency '$concurrency' (must be 'skip', 'queue_one', or 'replace')")
+            fi
+
+            # Fan-out needs a check command to print the items
+            local check_format item_key
+            check_format="$(config_trigger_field "$name" "check_format" 2>/dev/null || true)"
+            item_key="$(config_trigger_field "$name" "item_key" 2>/dev/null || true)"
+            if [[ -n "$check_format" && "$check_format" != "json_lines" && "$check_format" != "json" ]]; then
+                errors+=("Trigger '$name': invalid check_format '$check_format' (must be 'json_lines' or 'json')")
+            elif [[ -n "$check_format" && -z "$(config_trigger_field "$name" "check" 2>/dev/null || true)" ]]; then
+                errors+=("Trigger '$name': check_format needs a 'check' command that prints the items")
+            elif [[ -n "$check_format" && ! -x "$BIN_DIR/workmode-tui" ]]; then
+                warnings+=("Trigger '$name': fan-out needs the Go binary (run 'workmode install' with go available)")
+            fi
+            if [[ -n "$item_key" && -z "$check_format" ]]; then
+                warnings+=("Trigger '$name': item_key only applies with check_format")
+            fi
+
+            # Snapshot check
+            local snapshot
+            snapshot="$(config_trigger_field "$name" "snapshot" 2>/dev/null || true)"
+            if [[ -n "$snapshot" && "$snapshot" != "true" && "$snapshot" != "false" ]]; then
+                errors+=("Trigger '$name': snapshot must be true or false")
+            fi
+
+            # Timeout check
+            local timeout
+            timeout="$(config_trigger_field "$name" "timeout" 2>/dev/null || true)"
+            if [[ -n "$timeout" ]] && ! duration_to_seconds "$timeout" >/dev/null; then
+                errors+=("Trigger '$name': invalid timeout '$timeout' (e.g. '90s', '30m', '1h30m')")
+            fi
+
             # Retry check
             local retry
             retry="$(config_trigger_field "$name" "retry" 2>/dev/null || true)"
             if [[ -n "$retry" && "$retry" != "never" && "$retry" != "on_error" && "$retry" != "always" ]]; then
                 errors+=("Trigger '$name': invalid retry '$retry' (must be 'never', 'on_error', or 'always')")
             fi
+            local retry_backoff retry_delay_max
+            retry_backoff="$(config_trigger_field "$name" "retry_backoff" 2>/dev/null || true)"
+            if [[ -n "$retry_backoff" && "$retry_backoff" != "fixed" && "$retry_backoff" != "exponential" ]]; then
+                errors+=("Trigger '$name': invalid retry_backoff '$retry_backoff' (must be 'fixed' or 'exponential')")
+            fi
+            retry_delay_max="$(config_trigger_field "$name" "retry_delay_max" 2>/dev/null || true)"
+            if [[ -n "$retry_delay_max" && ! "$retry_delay_max" =~ ^[0-9]+$ ]]; then
+                errors+=("Trigger '$name': retry_delay_max must be a number of seconds")
+            elif [[ -n "$retry_delay_max" && "$retry_backoff" != "exponential" ]]; then
+                warnings+=("Trigger '$name': retry_delay_max only applies with retry_backoff = \"exponential\"")
+            fi
+
+            # Follow-up triggers must exist. The names are read up front:
+            # grep -q closing the pipe early would fail it under pipefail.
+            local on next names known
+            known="$(config_list_triggers)"
+            for on in success failure complete; do
+                IFS=', ' read -ra names <<< "$(config_trigger_field "$name" "on_$on" 2>/dev/null || true)"
+                for next in "${names[@]+"${names[@]}"}"; do
+                    [[ -z "$next" ]] && continue
+                    if ! grep -qxF "$next" <<< "$known"; then
+                        errors+=("Trigger '$name': on_$on names unknown trigger '$next'")
+                    fi
+                done
+            done
+
+            # Budget check
+            for budget_key in budget_daily_usd budget_monthly_usd; do
+                budget_value="$(config_trigger_field "$name" "$budget_key" 2>/dev/null || true)"
+                if [[ -n "$budget_value" && ! "$budget_value" =~ ^[0-9]+(\.[0-9]+)?$ ]]; then
+                    errors+=("Trigger '$name': $budget_key must be an amount in USD")
+                fi
+            done
         done
     fi
 
@@ -0,0 +1,107 @@
+#!/usr/bin/env bash
+# lib/cmd/history.sh — History maintenance commands
+
+dispatch_history() {
+    local subcmd="${1:-help}"
+    shift || true
+
+    case "$subcmd" in
+        compact) cmd_history_compact "$@" ;;
+        prune)   cmd_history_prune "$@" ;;
+        help|--help|-h) usage_history ;;
+        *)       die "Unknown history command: $subcmd" ;;
+    esac
+}
+
+usage_history() {
+    cat <<EOF
+Usage: workmode history <command> [options]
+
+Commands:
+  compact                 Rewrite history.jsonl with one line per session
+  prune [options]         Compact, then drop sessions outside the retention
+                          window and delete their log files
+
+Prune options:
+  --days <n>              Keep sessions started in the last n days
+  --per-trigger <n>       Keep the n newest sessions of each trigger
+  --dry-run               Show what would be removed
+  --quiet                 Only print errors
+
+Retention defaults come from retention_days / retention_per_trigger in
+[general]. Pinned, running, queued and stuck sessions are always kept.
+
+EOF
+    exit 0
+}
+
+cmd_history_compact() {
+    parse_global_flags "$@"
+    $SHOW_HELP && usage_history
+
+    local dropped result
+    dropped="$(mktemp)"
+    result="$(history_rewrite "" "" "$dropped")"
+    rm -f "$dropped"
+
+    local before="${result% *}" kept="${result#* }"
+    if [[ "$OUTPUT_FORMAT" == "json" ]]; then
+        json_object "$(json_field_num "lines_before" "$before"),$(json_field_num "lines_after" "$kept")"
+        echo
+        return
+    fi
+    echo "Compacted history: $before → $kept lines"
+}
+
+cmd_history_prune() {
+    local days="" per_trigger="" dry_run="" quiet=false
+    local args=()
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
+            --days)        days="${2:-}"; shift 2 || shift ;;
+            --per-trigger) per_trigger="${2:-}"; shift 2 || shift ;;
+            --dry-run)     dry_run="--dry-run"; shift ;;
+            --quiet|-q)    quiet=true; shift ;;
+            *)             args+=("$1"); shift ;;
+        esac
+    done
+    parse_global_flags "${args[@]+"${args[@]}"}"
+    $SHOW_HELP && usage_history
+
+    [[ -z "$days" ]] && days="$(config_retention_days)"
+    [[ -z "$per_trigger" ]] && per_trigger="$(config_retention_per_trigger)"
+    if [[ -n "$days" && ! "$days" =~ ^[0-9]+$ ]]; then
+        code=$EX_USAGE die "Invalid --days '$days' (expected a number of days)"
+    fi
+    if [[ -n "$per_trigger" && ! "$per_trigger" =~ ^[0-9]+$ ]]; then
+        code=$EX_USAGE die "Invalid --per-trigger '$per_trigger' (expected a number of sessions)"
+    fi
+
+    local dropped result logs
+    dropped="$(mktemp)"
+    result="$(history_rewrite "$days" "$per_trigger" "$dropped" "$dry_run")"
+    logs="$(history_remove_logs "$dropped" "$dry_run")"
+    history_remove_snapshots "$dropped" "$dry_run"
+
+    local before="${result% *}" kept="${result#* }"
+    local removed files="${logs% *}" bytes="${logs#* }"
+    removed="$(grep -c . "$dropped" || true)"
+
+    if [[ "$OUTPUT_FORMAT" == "json" ]]; then
+        local fields
+        fields="$(json_field_num "lines_before" "$before"),$(json_field_num "sessions_kept" "$kept")"
+        fields+=",$(json_field_num "sessions_removed" "$removed"),$(json_field_num "files_removed" "$files")"
+        fields+=",$(json_field_num "bytes_removed" "$bytes"),$(json_field_bool "dry_run" "$([[ -n "$dry_run" ]] && echo true || echo false)")"
+        json_object "$fields"
+        echo
+    elif ! $quiet; then
+        local compacted="Compacted" verb="Removed"
+        [[ -n "$dry_run" ]] && compacted="Would compact" verb="Would remove"
+        echo "$compacted history: $before → $kept lines"
+        echo "$verb $removed session(s) and $files log file(s) ($(format_bytes "$bytes"))"
+        if [[ -z "$days" && -z "$per_trigger" ]]; then
+            echo "No retention configured (set retention_days or retention_per_trigger in [general])."
+        fi
+    fi
+    rm -f "$dropped"
+}
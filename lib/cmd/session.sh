@@ -12,6 +12,14 @@ dispatch_session() {
         resume)  cmd_session_resume "$@" ;;
         stop)    cmd_session_stop "$@" ;;
         kill)    cmd_session_kill "$@" ;;
+        cancel)  cmd_session_cancel "$@" ;;
+        priority) cmd_session_priority "$@" ;;
+        pin)     cmd_session_pin "$@" ;;
+        unpin)   cmd_session_unpin "$@" ;;
+        export)  cmd_session_export "$@" ;;
+        revert)  cmd_session_revert "$@" ;;
+        approve) cmd_session_approve "$@" ;;
+        deny)    cmd_session_deny "$@" ;;
         help|--help|-h) usage_session ;;
         *)
             # If it looks like a session ID, treat as logs
@@ -35,15 +43,36 @@ Commands:
   resume <id>                                      Resume interactive session
   stop <id>                                        Graceful stop (SIGTERM)
   kill <id>                                        Force kill (SIGKILL)
+  cancel <id>                                      Remove a queued run
+  priority <id> <n|+n|-n>                          Set or adjust a queued run's priority
+  pin <id>                                         Keep a session through history pruning
+  unpin <id>                                       Let a pinned session be pruned again
+  export <id> [-f md|html|json] [-o path]          Write the transcript to a file
+  revert <id> [--dry-run] [--force]                Undo a session's changes to its git working dir
+  approve <id> [--allow <rule>]...                 Resume a stuck session with its refused tool calls allowed
+  deny <id>                                        Drop a stuck session's refused tool calls
 
 Options:
   --running       Show only running sessions
+  --queued        Show only queued sessions
   --stuck         Show only stuck sessions
   --completed     Show only completed sessions
   --all           Show all sessions (default: last 20)
   -n <count>      Number of sessions to show
   --json          Output as newline-delimited JSON
 
+Revert options:
+  -n, --dry-run   List the files and check for conflicts without changing anything
+  -f, --force     Restore the pre-run content even over later, conflicting changes
+
+Approve options:
+  --allow <rule>  Allow this tool rule, e.g. 'Bash(git push)' (repeatable;
+                  default: the calls the session was refused, read from its log)
+
+Export options:
+  -f, --format    md, html or json (default: from the -o extension, else md)
+  -o, --output    File to write, - for stdout (default: ./<short-id>.<format>)
+
 EOF
     exit 0
 }
@@ -56,6 +85,7 @@ cmd_session_list() {
     while [[ $# -gt 0 ]]; do
         case "$1" in
             --running)   filter="running"; shift ;;
+            --queued)    filter="queued"; shift ;;
             --stuck)     filter="stuck"; shift ;;
             --completed) filter="completed"; shift ;;
             --all)       count=0; shift ;;
@@ -142,6 +172,126 @@ cmd_session_list() {
     done
 }
 
+# Print the approval state of a session that was refused tool calls, or
+# the session it resumed with them allowed.
+# Usage: show_approval <history_line>
+show_approval() {
+    local line="$1" denials approval resume_of resumed_by
+    denials="$(sess_json_field_num "$line" "denials")"
+    approval="$(sess_json_field "$line" "approval")"
+    resume_of="$(sess_json_field "$line" "resume_of")"
+    resumed_by="$(sess_json_field "$line" "resumed_by")"
+    [[ -n "$resume_of" ]] && echo "Resumes:  $(_session_short "$resume_of") (with refused tool calls allowed)"
+    if [[ -n "$approval" ]]; then
+        echo "Approval: $approval${resumed_by:+, resumed as $(_session_short "$resumed_by")}"
+    elif [[ -n "$denials" || "$(sess_json_field "$line" "status")" == "stuck" ]]; then
+        echo -e "Approval: ${YELLOW}pending${RESET}${denials:+, $denials refused tool call(s)}"
+    fi
+}
+
+# Print the run a session was chained from and the runs it chained to.
+# Usage: show_chain <history_line> <full_id>
+show_chain() {
+    local line="$1" full_id="$2" parent chain next="" id child
+    parent="$(sess_json_field "$line" "parent")"
+    chain="$(sess_json_field "$line" "chain")"
+    [[ -n "$parent" ]] && echo "Parent:   $(_session_short "$parent") (chained on $chain)"
+    while IFS= read -r id; do
+        child="$(resolve_session "$id" "$HISTORY_FILE" 2>/dev/null || true)"
+        [[ -n "$child" ]] || continue
+        next+="${next:+, }$(sess_json_field "$child" "short") (on $(sess_json_field "$child" "chain"))"
+    done < <(_runs_started_by "parent" "$full_id")
+    [[ -n "$next" ]] && echo "Chained:  $next"
+    return 0
+}
+
+# Print the fan-out run an item run belongs to, or the item runs a fan-out
+# run queued, with their status.
+# Usage: show_fanout <history_line> <full_id>
+show_fanout() {
+    local line="$1" full_id="$2" fanout_of items="" id child
+    fanout_of="$(sess_json_field "$line" "fanout_of")"
+    [[ -n "$fanout_of" ]] && echo "Item:     $(sess_json_field "$line" "item_key") (fanned out by $(_session_short "$fanout_of"))"
+    [[ -n "$(sess_json_field_num "$line" "fanout")" ]] || return 0
+    while IFS= read -r id; do
+        child="$(resolve_session "$id" "$HISTORY_FILE" 2>/dev/null || true)"
+        [[ -n "$child" ]] || continue
+        items+="${items:+, }$(sess_json_field "$child" "short") ($(sess_json_field "$child" "status"))"
+    done < <(_runs_started_by "fanout_of" "$full_id")
+    echo "Fan-out:  ${items:-$(sess_json_field_num "$line" "fanout") item runs, no longer in history}"
+}
+
+# Print the webhook request a session was started for, from the record
+# kept next to its first attempt's log.
+# Usage: show_request <history_line> <full_id>
+show_request() {
+    local line="$1" full_id="$2" request root record remote
+    request="$(sess_json_field "$line" "request")"
+    [[ -n "$request" ]] || return 0
+    root="$(sess_json_field "$line" "retry_of")"
+    record="$(cat "$LOG_DIR/${root:-$full_id}.request" 2>/dev/null || true)"
+    if [[ -z "$record" ]]; then
+        echo "Request:  $request"
+        return 0
+    fi
+    remote="$(sess_json_field "$record" "remote")"
+    [[ -z "$remote" || "$remote" == "@" ]] && remote="unix socket"
+    echo "Request:  $request from $remote at $(format_time "$(sess_json_field "$record" "received")"), $(sess_json_field_num "$record" "size") bytes, verified by $(sess_json_field "$record" "verified")"
+}
+
+# Print the IDs of the runs a session started, through a history field
+# naming it (parent, fanout_of): the first attempt of each, since retries
+# carry the field too.
+# Usage: _runs_started_by <field> <full_id>
+_runs_started_by() {
+    grep -F "\"$1\":\"$2\"" "$HISTORY_FILE" 2>/dev/null \
+        | grep -v '"retry_of"' | sed -n 's/^{"id":"\([^"]*\)".*/\1/p' | awk '!seen[$0]++'
+}
+
+# Print the short ID of a session, or the ID given if it's no longer in
+# history.
+# Usage: _session_short <id>
+_session_short() {
+    local line short=""
+    line="$(resolve_session "$1" "$HISTORY_FILE" 2>/dev/null || true)"
+    [[ -n "$line" ]] && short="$(sess_json_field "$line" "short")"
+    echo "${short:-$1}"
+}
+
+# Print the git state workmode-run recorded for a session's working dir:
+# HEAD before and after, and what changed in between.
+# Usage: show_git_snapshot <history_line> <full_id>
+show_git_snapshot() {
+    local line="$1" full_id="$2" before after branch files ins del commits
+    [[ "$line" == *'"git_tree_before"'* ]] || return 0
+    before="$(sess_json_field "$line" "git_head_before")"
+    after="$(sess_json_field "$line" "git_head_after")"
+    branch="$(sess_json_field "$line" "git_branch_before")"
+    files="$(sess_json_field_num "$line" "git_files_changed")"
+    ins="$(sess_json_field_num "$line" "git_insertions")"
+    del="$(sess_json_field_num "$line" "git_deletions")"
+    commits="$(sess_json_field_num "$line" "git_commits")"
+
+    local head="${before:0:7}"
+    [[ -z "$head" ]] && head="(no commits)"
+    [[ -n "$after" && "$after" != "$before" ]] && head+=" → ${after:0:7}"
+    [[ "$line" == *'"git_dirty_before":true'* ]] && head+=" (dirty before run)"
+    echo "Git:      ${branch:-(detached)} $head"
+
+    if [[ -n "$files" ]]; then
+        local changes="$files file$( (( files == 1 )) || echo s) changed, +${ins:-0} -${del:-0}"
+        [[ -n "$commits" ]] && changes+=", $commits commit$( (( commits == 1 )) || echo s)"
+        echo "Changes:  $changes"
+        [[ -f "$LOG_DIR/${full_id}.diff" ]] && echo "Diff:     $LOG_DIR/${full_id}.diff"
+    elif [[ "$line" == *'"git_tree_after"'* ]]; then
+        echo "Changes:  none"
+    fi
+    local reverted
+    reverted="$(sess_json_field "$line" "reverted")"
+    [[ -n "$reverted" ]] && echo "Reverted: $(format_time "$reverted")"
+    return 0
+}
+
 cmd_session_logs() {
     local target_id="${1:-}"
     [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session logs <session-id>"; }
@@ -151,7 +301,8 @@ cmd_session_logs() {
     session_line="$(resolve_session "$target_id" "$HISTORY_FILE" 2>/dev/null || true)"
 
     if [[ -n "$session_line" ]]; then
-        local full_id status trigger started duration exit_code attempt session_id working_dir
+        local full_id status trigger started duration exit_code attempt retry_max retry_at session_id working_dir
+        local cost turns tokens_in tokens_out cache_read cache_write
         full_id="$(sess_json_field "$session_line" "id")"
         status="$(sess_json_field "$session_line" "status")"
         trigger="$(sess_json_field "$session_line" "trigger")"
@@ -159,6 +310,14 @@ cmd_session_logs() {
         duration="$(sess_json_field_num "$session_line" "duration")"
         exit_code="$(sess_json_field_num "$session_line" "exit_code")"
         attempt="$(sess_json_field_num "$session_line" "attempt")"
+        retry_max="$(sess_json_field_num "$session_line" "retry_max")"
+        retry_at="$(sess_json_field "$session_line" "retry_at")"
+        cost="$(grep -oP '"cost_usd":\K[0-9.]+' <<< "$session_line" || true)"
+        turns="$(sess_json_field_num "$session_line" "num_turns")"
+        tokens_in="$(sess_json_field_num "$session_line" "input_tokens")"
+        tokens_out="$(sess_json_field_num "$session_line" "output_tokens")"
+        cache_read="$(sess_json_field_num "$session_line" "cache_read_input_tokens")"
+        cache_write="$(sess_json_field_num "$session_line" "cache_creation_input_tokens")"
         session_id="$(sess_json_field "$session_line" "session_id")"
         working_dir="$(sess_json_field "$session_line" "working_dir")"
 
@@ -168,15 +327,39 @@ cmd_session_logs() {
         echo "Started:  $(format_time "$started")"
         [[ -n "$duration" && "$duration" != "0" ]] && echo "Duration: $(format_duration "$duration")"
         [[ -n "$working_dir" ]] && echo "Dir:      $working_dir"
+        show_git_snapshot "$session_line" "$full_id"
         [[ -n "$session_id" ]] && echo "Claude:   $session_id"
+        [[ "$session_line" == *'"pinned":true'* ]] && echo "Pinned:   yes"
+        [[ -n "$cost" ]] && printf 'Cost:     $%.2f\n' "$cost"
+        if [[ -n "$turns" || -n "$tokens_out" ]]; then
+            # Cached prompt tokens count as input
+            tokens_in=$(( ${tokens_in:-0} + ${cache_read:-0} + ${cache_write:-0} ))
+            echo "Usage:    ${turns:-0} turns, $(format_tokens "$tokens_in") in, $(format_tokens "${tokens_out:-0}") out"
+        fi
         [[ -n "$exit_code" ]] && echo -e "Exit:     ${RED}${exit_code}${RESET}"
-        [[ -n "$attempt" && "$attempt" != "1" ]] && echo "Attempt:  $attempt"
+        [[ "$status" == "error" && -n "$(sess_json_field "$session_line" "error")" ]] && \
+            echo -e "Error:    ${RED}$(sess_json_field "$session_line" "error")${RESET}"
+        [[ -n "$attempt" && "$attempt" != "1" ]] && echo "Attempt:  $attempt${retry_max:+/$retry_max}"
+        [[ -n "$retry_at" ]] && echo "Retry:    retry $(( ${attempt:-1} + 1 ))${retry_max:+/$retry_max} at $(format_time "$retry_at")"
+        show_approval "$session_line"
+        show_chain "$session_line" "$full_id"
+        show_fanout "$session_line" "$full_id"
+        show_request "$session_line" "$full_id"
         echo ""
     else
         echo -e "${BOLD}Session: ${target_id}${RESET}"
         echo ""
     fi
 
+    # The prompt as sent; runs from before prompts were saved have none
+    local prompt_file="$LOG_DIR/${full_id:-$target_id}.prompt"
+    if [[ -s "$prompt_file" ]]; then
+        echo -e "${DIM}─── Prompt ───${RESET}"
+        echo ""
+        cat "$prompt_file"
+        echo ""
+    fi
+
     local log_file
     log_file="$(find_session_log "$target_id" "$LOG_DIR" "$HISTORY_FILE" 2>/dev/null || true)"
 
@@ -278,12 +461,309 @@ cmd_session_stop() {
     local target_id="${1:-}"
     [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session stop <id>"; }
     terminate_session "$target_id" "stop" "$HISTORY_FILE" "$STATE_DIR"
+    "$BIN_DIR/workmode-run" --drain
 }
 
 cmd_session_kill() {
     local target_id="${1:-}"
     [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session kill <id>"; }
     terminate_session "$target_id" "kill" "$HISTORY_FILE" "$STATE_DIR"
+    "$BIN_DIR/workmode-run" --drain
+}
+
+# Resolve a queued session and its queue entry, or die.
+# Sets: session_line, short_id, entry
+_resolve_queued() {
+    local target_id="$1"
+    session_line="$(resolve_session "$target_id" "$HISTORY_FILE")" || {
+        code=$EX_NOT_FOUND die "Session '$target_id' not found."
+    }
+    local full_id status
+    full_id="$(sess_json_field "$session_line" "id")"
+    status="$(sess_json_field "$session_line" "status")"
+    short_id="$(sess_json_field "$session_line" "short")"
+    [[ -z "$short_id" ]] && short_id="$full_id"
+
+    if [[ "$status" != "queued" ]]; then
+        code=$EX_STATE die "Session $short_id is not queued (status: $status)."
+    fi
+    entry="$(queue_entry "$full_id")" || {
+        code=$EX_STATE die "Session $short_id has already left the queue."
+    }
+}
+
+cmd_session_cancel() {
+    local target_id="${1:-}"
+    [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session cancel <id>"; }
+
+    local session_line short_id entry
+    _resolve_queued "$target_id"
+    rm -f "$entry"
+    history_append "$HISTORY_FILE" "${session_line/\"status\":\"queued\"/\"status\":\"cancelled\"}"
+    echo "Cancelled queued session $short_id."
+}
+
+cmd_session_priority() {
+    local target_id="${1:-}" value="${2:-}"
+    if [[ -z "$target_id" || ! "$value" =~ ^[+-]?[0-9]+$ ]]; then
+        code=$EX_USAGE die "Usage: workmode session priority <id> <n|+n|-n>"
+    fi
+
+    local session_line short_id entry current priority
+    _resolve_queued "$target_id"
+    current="$(grep -oP '"priority":\K-?[0-9]+' "$entry" || echo 0)"
+    case "$value" in
+        [+-]*) priority=$(( current + value )) ;;
+        *)     priority="$value" ;;
+    esac
+
+    queue_set_priority "$(sess_json_field "$session_line" "id")" "$priority"
+    # Record the change so the session list shows the new priority
+    history_append "$HISTORY_FILE" "$(echo "$session_line" | sed -E "s/\"priority\":-?[0-9]+/\"priority\":${priority}/")"
+    echo "Session $short_id priority: $current → $priority"
+}
+
+cmd_session_pin() {
+    _session_set_pinned "${1:-}" true
+}
+
+cmd_session_unpin() {
+    _session_set_pinned "${1:-}" false
+}
+
+# Record a session's pinned flag in history (last line wins)
+_session_set_pinned() {
+    local target_id="$1" pinned="$2" verb="pin"
+    $pinned || verb="unpin"
+    [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session $verb <id>"; }
+
+    local session_line status short_id
+    session_line="$(resolve_session "$target_id" "$HISTORY_FILE")" || {
+        code=$EX_NOT_FOUND die "Session '$target_id' not found."
+    }
+    status="$(sess_json_field "$session_line" "status")"
+    short_id="$(sess_json_field "$session_line" "short")"
+    [[ -z "$short_id" ]] && short_id="$(sess_json_field "$session_line" "id")"
+
+    # The runner rewrites the whole line when the run ends, dropping the flag
+    if [[ "$status" == "running" || "$status" == "queued" ]]; then
+        code=$EX_STATE die "Session $short_id is still $status; $verb it once it has finished."
+    fi
+
+    session_line="${session_line/,\"pinned\":true/}"
+    if $pinned; then
+        session_line="${session_line%\}},\"pinned\":true}"
+        echo "Pinned session $short_id; history pruning will keep it."
+    else
+        echo "Unpinned session $short_id."
+    fi
+    history_append "$HISTORY_FILE" "$session_line"
+}
+
+# Write a session transcript as Markdown, HTML or JSON (done by the Go binary,
+# which parses the stream-json log)
+cmd_session_export() {
+    local target_id="" format="" output=""
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
+            -f|--format) format="${2:-}"; shift 2 || shift ;;
+            -o|--output) output="${2:-}"; shift 2 || shift ;;
+            -h|--help)   usage_session ;;
+            *)           target_id="$1"; shift ;;
+        esac
+    done
+    [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session export <id> [--format md|html|json] [--output path]"; }
+    if [[ -n "$format" && ! "$format" =~ ^(md|markdown|html|htm|json)$ ]]; then
+        code=$EX_USAGE die "Invalid --format '$format' (expected md, html or json)"
+    fi
+
+    local session_line full_id
+    session_line="$(resolve_session "$target_id" "$HISTORY_FILE")" || {
+        code=$EX_NOT_FOUND die "Session '$target_id' not found."
+    }
+    full_id="$(sess_json_field "$session_line" "id")"
+
+    [[ -x "$BIN_DIR/workmode-tui" ]] || {
+        code=$EX_DEPENDENCY die "session export needs the Go binary — run 'workmode install' with go available"
+    }
+    local args=()
+    [[ -n "$format" ]] && args+=(--format "$format")
+    [[ -n "$output" ]] && args+=(--output "$output")
+    exec "$BIN_DIR/workmode-tui" export "${args[@]+"${args[@]}"}" "$full_id"
+}
+
+# Undo a session's changes to its git working dir: the files it changed get
+# their pre-run content back, from the diff and snapshots workmode-run saved.
+# Refuses when later edits conflict, unless --force, which overwrites them.
+cmd_session_revert() {
+    local target_id="" force=false dry_run=false
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
+            -f|--force)   force=true; shift ;;
+            -n|--dry-run) dry_run=true; shift ;;
+            -h|--help)    usage_session ;;
+            *)            target_id="$1"; shift ;;
+        esac
+    done
+    [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session revert <id> [--dry-run] [--force]"; }
+
+    local session_line status short_id full_id working_dir reverted commits diff_file
+    session_line="$(resolve_session "$target_id" "$HISTORY_FILE")" || {
+        code=$EX_NOT_FOUND die "Session '$target_id' not found."
+    }
+    status="$(sess_json_field "$session_line" "status")"
+    full_id="$(sess_json_field "$session_line" "id")"
+    short_id="$(sess_json_field "$session_line" "short")"
+    [[ -z "$short_id" ]] && short_id="$full_id"
+    working_dir="$(sess_json_field "$session_line" "working_dir")"
+    reverted="$(sess_json_field "$session_line" "reverted")"
+    commits="$(sess_json_field_num "$session_line" "git_commits")"
+    diff_file="$LOG_DIR/${full_id}.diff"
+
+    if [[ "$status" == "running" || "$status" == "queued" ]]; then
+        code=$EX_STATE die "Session $short_id is still $status; revert it once it has finished."
+    fi
+    if [[ "$session_line" != *'"git_tree_after"'* ]]; then
+        code=$EX_STATE die "Session $short_id has no git snapshot (its working dir is not in a git repo, or it ran before snapshots were recorded)."
+    fi
+    if [[ -n "$reverted" ]] && ! $force; then
+        code=$EX_STATE die "Session $short_id was already reverted ($(format_time "$reverted"))."
+    fi
+    if [[ ! -f "$diff_file" ]]; then
+        echo "Session $short_id changed no files; nothing to revert."
+        return 0
+    fi
+    [[ -d "$working_dir" ]] || { code=$EX_STATE die "Working dir $working_dir no longer exists."; }
+
+    local conflicts="" clean=true
+    conflicts="$(snapshot_can_revert "$working_dir" "$diff_file")" || clean=false
+
+    if $dry_run; then
+        echo "Session $short_id changed:"
+        git apply -R --stat "$diff_file" | sed 's/^/  /'
+        if $clean; then
+            echo "Reverts cleanly."
+        else
+            echo "Later changes conflict:"
+            echo "$conflicts" | sed 's/^/  /'
+            if snapshot_available "$working_dir" \
+                "$(sess_json_field "$session_line" "git_tree_before")" \
+                "$(sess_json_field "$session_line" "git_tree_after")"; then
+                echo "Use --force to restore the pre-run content anyway."
+            else
+                echo "Its snapshot is no longer available (git gc removed it), so --force can't restore it."
+            fi
+        fi
+        return 0
+    fi
+
+    if ! $clean && ! $force; then
+        echo "$conflicts" | sed 's/^/  /' >&2
+        code=$EX_STATE die "Later changes conflict with session $short_id's; nothing was reverted. Use --force to restore the pre-run content anyway."
+    fi
+
+    if $clean; then
+        snapshot_revert "$working_dir" "$diff_file" || die "Reverting session $short_id failed."
+    else
+        # --force restores from the snapshots rather than the diff
+        local tree_before tree_after
+        tree_before="$(sess_json_field "$session_line" "git_tree_before")"
+        tree_after="$(sess_json_field "$session_line" "git_tree_after")"
+        snapshot_available "$working_dir" "$tree_before" "$tree_after" || {
+            code=$EX_STATE die "Session $short_id's snapshot is no longer available in $working_dir (git gc removed it); nothing was reverted."
+        }
+        snapshot_restore "$working_dir" "$tree_before" "$tree_after" \
+            || die "Restoring session $short_id's files failed."
+    fi
+
+    # Record the revert; last line wins
+    session_line="$(sed -E 's/,"reverted":"[^"]*"//' <<< "$session_line")"
+    history_append "$HISTORY_FILE" "${session_line%\}},$(json_field "reverted" "$(date -Iseconds)")}"
+
+    local files
+    files="$(git apply --numstat "$diff_file" | wc -l)"
+    echo "Reverted session $short_id: $files file$( (( files == 1 )) || echo s) restored in $working_dir."
+    if [[ "$commits" == 1 ]]; then
+        echo "The commit it made is kept; the revert is left as uncommitted changes."
+    elif [[ -n "$commits" ]]; then
+        echo "The $commits commits it made are kept; the revert is left as uncommitted changes."
+    fi
+}
+
+# Resolve a session that is waiting for refused tool calls to be approved,
+# dying if it isn't. Prints its history line.
+# Usage: _session_pending_approval <id> <verb>
+_session_pending_approval() {
+    local target_id="$1" verb="$2" session_line status short_id approval
+    session_line="$(resolve_session "$target_id" "$HISTORY_FILE")" || {
+        code=$EX_NOT_FOUND die "Session '$target_id' not found."
+    }
+    status="$(sess_json_field "$session_line" "status")"
+    short_id="$(sess_json_field "$session_line" "short")"
+    approval="$(sess_json_field "$session_line" "approval")"
+    if [[ "$status" == "running" || "$status" == "queued" ]]; then
+        code=$EX_STATE die "Session $short_id is still $status."
+    fi
+    [[ -n "$approval" ]] && { code=$EX_STATE die "Session $short_id was already $approval."; }
+    if [[ -z "$(sess_json_field "$session_line" "session_id")" ]]; then
+        code=$EX_STATE die "Session $short_id has no Claude session to resume."
+    fi
+    if [[ "$status" != "stuck" && "$session_line" != *'"denials":'* ]]; then
+        code=$EX_STATE die "Session $short_id wasn't refused any tool calls; nothing to $verb."
+    fi
+    echo "$session_line"
+}
+
+# Resume a session that was refused tool calls, with them allowed. The
+# follow-up is a new run of the same trigger, linked to this session.
+cmd_session_approve() {
+    local target_id="" rules=()
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
+            --allow)   [[ -n "${2:-}" ]] || { code=$EX_USAGE die "--allow needs a rule"; }
+                       rules+=("$2"); shift 2 ;;
+            -h|--help) usage_session ;;
+            *)         target_id="$1"; shift ;;
+        esac
+    done
+    [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session approve <id> [--allow <rule>]..."; }
+
+    local session_line full_id short_id trigger rule
+    session_line="$(_session_pending_approval "$target_id" "approve")" || exit $?
+    full_id="$(sess_json_field "$session_line" "id")"
+    short_id="$(sess_json_field "$session_line" "short")"
+    trigger="$(sess_json_field "$session_line" "trigger")"
+
+    if (( ${#rules[@]} == 0 )); then
+        [[ -x "$BIN_DIR/workmode-tui" ]] || {
+            code=$EX_DEPENDENCY die "Reading refused calls from the log needs the Go binary; pass --allow <rule>, or run 'workmode install' with go available"
+        }
+        while IFS= read -r -d '' rule; do
+            rules+=("$rule")
+        done < <("$BIN_DIR/workmode-tui" denials -0 "$full_id")
+        (( ${#rules[@]} > 0 )) || {
+            code=$EX_STATE die "No refused tool calls found in $short_id's log; pass --allow <rule>, or resume it with 'workmode session resume $short_id'."
+        }
+    fi
+
+    echo "Resuming $short_id with: ${rules[*]}"
+    local args=(--trigger "$trigger" --resume-of "$full_id")
+    for rule in "${rules[@]}"; do
+        args+=(--allow "$rule")
+    done
+    exec "$BIN_DIR/workmode-run" "${args[@]}"
+}
+
+# Mark a session's refused tool calls as denied, taking it off the
+# approvals list
+cmd_session_deny() {
+    local target_id="${1:-}"
+    [[ -z "$target_id" ]] && { code=$EX_USAGE die "Usage: workmode session deny <id>"; }
+
+    local session_line
+    session_line="$(_session_pending_approval "$target_id" "deny")" || exit $?
+    history_append "$HISTORY_FILE" "${session_line%\}},\"approval\":\"denied\"}"
+    echo "Denied session $(sess_json_field "$session_line" "short")."
 }
 
 # Alias for sessions.sh json_field to avoid conflict with cli.sh json_field
@@ -89,13 +89,16 @@ cmd_trigger_show() {
         return
     fi
 
-    local type skill prompt_text permissions working_dir cooldown check
+    local type skill prompt_text permissions working_dir cooldown check priority concurrency timeout
     type="$(config_trigger_field "$trigger_name" "type" || echo "?")"
     skill="$(config_trigger_field "$trigger_name" "skill" || true)"
     prompt_text="$(config_trigger_field "$trigger_name" "prompt" || true)"
     permissions="$(config_trigger_field "$trigger_name" "permissions" || echo "default")"
     working_dir="$(config_trigger_field "$trigger_name" "working_dir" || true)"
     cooldown="$(config_trigger_field "$trigger_name" "cooldown" || true)"
+    priority="$(config_trigger_field "$trigger_name" "priority" || true)"
+    concurrency="$(config_trigger_field "$trigger_name" "concurrency" || true)"
+    timeout="$(config_trigger_field "$trigger_name" "timeout" || true)"
     check="$(config_trigger_field "$trigger_name" "check" || true)"
 
     echo "Trigger:     $trigger_name"
@@ -112,26 +115,64 @@ cmd_trigger_show() {
         [[ -n "$interval" ]] && echo "Interval:    $interval"
         [[ -n "$cron_expr" ]] && echo "Cron:        $cron_expr"
     elif [[ "$type" == "file" ]]; then
-        local watch pattern settle
+        local watch pattern settle batch_window
         watch="$(config_trigger_field "$trigger_name" "watch" || true)"
         pattern="$(config_trigger_field "$trigger_name" "pattern" || true)"
         settle="$(config_trigger_field "$trigger_name" "settle" || true)"
+        batch_window="$(config_trigger_field "$trigger_name" "batch_window" || true)"
         [[ -n "$watch" ]] && echo "Watch:       $watch"
         [[ -n "$pattern" ]] && echo "Pattern:     $pattern"
         [[ -n "$settle" ]] && echo "Settle:      ${settle}s"
+        [[ -n "$batch_window" ]] && echo "Batch:       ${batch_window}s"
+    elif [[ "$type" == "webhook" ]]; then
+        echo "Endpoint:    $(_webhook_endpoint "$trigger_name")"
+        if config_trigger_field "$trigger_name" "secret" &>/dev/null; then
+            echo "Secret:      set"
+        else
+            echo "Secret:      missing — requests are refused"
+        fi
     fi
 
     [[ -n "$cooldown" ]] && echo "Cooldown:    ${cooldown}s"
+    [[ -n "$priority" ]] && echo "Priority:    $priority"
+    [[ -n "$concurrency" ]] && echo "Concurrency: $concurrency"
+    [[ -n "$timeout" ]] && echo "Timeout:     $timeout"
     [[ -n "$check" ]] && echo "Check:       $check"
+    local check_format item_key
+    check_format="$(config_trigger_field "$trigger_name" "check_format" || true)"
+    item_key="$(config_trigger_field "$trigger_name" "item_key" || true)"
+    [[ -n "$check_format" ]] && echo "Fan-out:     a run per item ($check_format)${item_key:+, by $item_key}"
 
     # Retry settings
-    local retry retry_max retry_delay
+    local retry retry_max retry_delay retry_backoff retry_delay_max
     retry="$(config_trigger_field "$trigger_name" "retry" || true)"
     retry_max="$(config_trigger_field "$trigger_name" "retry_max" || true)"
     retry_delay="$(config_trigger_field "$trigger_name" "retry_delay" || true)"
+    retry_backoff="$(config_trigger_field "$trigger_name" "retry_backoff" || true)"
+    retry_delay_max="$(config_trigger_field "$trigger_name" "retry_delay_max" || true)"
     [[ -n "$retry" ]] && echo "Retry:       $retry"
     [[ -n "$retry_max" ]] && echo "Retry max:   $retry_max"
     [[ -n "$retry_delay" ]] && echo "Retry delay: ${retry_delay}s"
+    [[ -n "$retry_backoff" ]] && echo "Backoff:     $retry_backoff"
+    [[ -n "$retry_delay_max" ]] && echo "Delay max:   ${retry_delay_max}s"
+
+    # Follow-up triggers
+    local on next
+    for on in success failure complete; do
+        next="$(config_trigger_field "$trigger_name" "on_$on" || true)"
+        [[ -n "$next" ]] && printf '%-13s%s\n' "On $on:" "$next"
+    done
+
+    # Spend budgets
+    local budget_daily budget_monthly
+    budget_daily="$(config_trigger_field "$trigger_name" "budget_daily_usd" || true)"
+    budget_monthly="$(config_trigger_field "$trigger_name" "budget_monthly_usd" || true)"
+    [[ -n "$budget_daily" ]] && printf 'Daily cap:   $%.2f\n' "$budget_daily"
+    [[ -n "$budget_monthly" ]] && printf 'Monthly cap: $%.2f\n' "$budget_monthly"
+    local budget_reason
+    if budget_reason="$(budget_exceeded "$trigger_name")"; then
+        echo "Budget:      paused — $budget_reason"
+    fi
 
     # Systemd unit status
     local unit_name="${UNIT_PREFIX}${trigger_name}"
@@ -167,7 +208,7 @@ cmd_trigger_enable() {
         echo "Enabled timer: $trigger_name"
     else
         echo "Only timer triggers can be individually enabled/disabled."
-        echo "File triggers are managed by the watcher service."
+        echo "File triggers are managed by the watcher service, webhook triggers by the webhook service."
     fi
 }
 
@@ -188,12 +229,23 @@ cmd_trigger_disable() {
         echo "Disabled timer: $trigger_name"
     else
         echo "Only timer triggers can be individually enabled/disabled."
-        echo "File triggers are managed by the watcher service."
+        echo "File triggers are managed by the watcher service, webhook triggers by the webhook service."
     fi
 }
 
 # --- Helpers ---
 
+# Where a webhook trigger takes requests: a URL, or the path on a unix socket
+_webhook_endpoint() {
+    local name="$1" listen
+    listen="$(config_webhook_listen)"
+    case "$listen" in
+        unix:*)  echo "POST /hooks/$name on ${listen#unix:}" ;;
+        /*)      echo "POST /hooks/$name on $listen" ;;
+        *)       echo "POST http://$listen/hooks/$name" ;;
+    esac
+}
+
 _trigger_schedule_info() {
     local name="$1" type="$2"
     if [[ "$type" == "timer" ]]; then
@@ -211,12 +263,14 @@ _trigger_schedule_info() {
         watch="$(config_trigger_field "$name" "watch" || echo "?")"
         pattern="$(config_trigger_field "$name" "pattern" || echo "*")"
         echo "$watch ($pattern)"
+    elif [[ "$type" == "webhook" ]]; then
+        echo "POST /hooks/$name"
     fi
 }
 
 _trigger_to_json() {
     local name="$1"
-    local type skill prompt_text permissions working_dir cooldown check
+    local type skill prompt_text permissions working_dir cooldown check priority concurrency timeout
 
     type="$(config_trigger_field "$name" "type" || echo "")"
     skill="$(config_trigger_field "$name" "skill" || true)"
@@ -224,6 +278,9 @@ _trigger_to_json() {
     permissions="$(config_trigger_field "$name" "permissions" || echo "default")"
     working_dir="$(config_trigger_field "$name" "working_dir" || true)"
     cooldown="$(config_trigger_field "$name" "cooldown" || true)"
+    priority="$(config_trigger_field "$name" "priority" || true)"
+    concurrency="$(config_trigger_field "$name" "concurrency" || true)"
+    timeout="$(config_trigger_field "$name" "timeout" || true)"
     check="$(config_trigger_field "$name" "check" || true)"
 
     local fields
@@ -232,7 +289,15 @@ _trigger_to_json() {
     [[ -n "$prompt_text" ]] && fields+=",$(json_field "prompt" "$prompt_text")"
     [[ -n "$working_dir" ]] && fields+=",$(json_field "working_dir" "$working_dir")"
     [[ -n "$cooldown" ]] && fields+=",$(json_field_num "cooldown" "$cooldown")"
+    [[ -n "$priority" ]] && fields+=",$(json_field_num "priority" "$priority")"
+    [[ -n "$concurrency" ]] && fields+=",$(json_field "concurrency" "$concurrency")"
+    [[ -n "$timeout" ]] && fields+=",$(json_field "timeout" "$timeout")"
     [[ -n "$check" ]] && fields+=",$(json_field "check" "$check")"
+    local check_format item_key
+    check_format="$(config_trigger_field "$name" "check_format" || true)"
+    item_key="$(config_trigger_field "$name" "item_key" || true)"
+    [[ -n "$check_format" ]] && fields+=",$(json_field "check_format" "$check_format")"
+    [[ -n "$item_key" ]] && fields+=",$(json_field "item_key" "$item_key")"
 
     if [[ "$type" == "timer" ]]; then
         local interval cron_expr
@@ -241,22 +306,40 @@ _trigger_to_json() {
         [[ -n "$interval" ]] && fields+=",$(json_field "interval" "$interval")"
         [[ -n "$cron_expr" ]] && fields+=",$(json_field "cron" "$cron_expr")"
     elif [[ "$type" == "file" ]]; then
-        local watch pattern settle
+        local watch pattern settle batch_window
         watch="$(config_trigger_field "$name" "watch" || true)"
         pattern="$(config_trigger_field "$name" "pattern" || true)"
         settle="$(config_trigger_field "$name" "settle" || true)"
+        batch_window="$(config_trigger_field "$name" "batch_window" || true)"
         [[ -n "$watch" ]] && fields+=",$(json_field "watch" "$watch")"
         [[ -n "$pattern" ]] && fields+=",$(json_field "pattern" "$pattern")"
         [[ -n "$settle" ]] && fields+=",$(json_field_num "settle" "$settle")"
+        [[ -n "$batch_window" ]] && fields+=",$(json_field_num "batch_window" "$batch_window")"
     fi
 
-    local retry retry_max retry_delay
+    local retry retry_max retry_delay retry_backoff retry_delay_max
     retry="$(config_trigger_field "$name" "retry" || true)"
     retry_max="$(config_trigger_field "$name" "retry_max" || true)"
     retry_delay="$(config_trigger_field "$name" "retry_delay" || true)"
+    retry_backoff="$(config_trigger_field "$name" "retry_backoff" || true)"
+    retry_delay_max="$(config_trigger_field "$name" "retry_delay_max" || true)"
     [[ -n "$retry" ]] && fields+=",$(json_field "retry" "$retry")"
     [[ -n "$retry_max" ]] && fields+=",$(json_field_num "retry_max" "$retry_max")"
     [[ -n "$retry_delay" ]] && fields+=",$(json_field_num "retry_delay" "$retry_delay")"
+    [[ -n "$retry_backoff" ]] && fields+=",$(json_field "retry_backoff" "$retry_backoff")"
+    [[ -n "$retry_delay_max" ]] && fields+=",$(json_field_num "retry_delay_max" "$retry_delay_max")"
+
+    local on next
+    for on in success failure complete; do
+        next="$(config_trigger_field "$name" "on_$on" || true)"
+        [[ -n "$next" ]] && fields+=",$(json_field "on_$on" "$next")"
+    done
+
+    local budget_daily budget_monthly
+    budget_daily="$(config_trigger_field "$name" "budget_daily_usd" || true)"
+    budget_monthly="$(config_trigger_field "$name" "budget_monthly_usd" || true)"
+    [[ -n "$budget_daily" ]] && fields+=",$(json_field_num "budget_daily_usd" "$budget_daily")"
+    [[ -n "$budget_monthly" ]] && fields+=",$(json_field_num "budget_monthly_usd" "$budget_monthly")"
 
     json_object "$fields"
     echo
@@ -161,9 +161,8 @@ _tui_preview_session() {
         pid="$(sess_json_field_num "$session_line" "pid")"
         if [[ -n "$pid" ]] && ! kill -0 "$pid" 2>/dev/null; then
             local dur=$(( $(date +%s) - $(date -d "$started" +%s 2>/dev/null || echo "$(date +%s)") ))
-            printf '{"id":"%s","short":"%s","trigger":"%s","working_dir":"%s","started":"%s","status":"error","duration":%d}\n' \
-                "$full_id" "$short_id" "$trigger" "$working_dir" "$started" "$dur" \
-                >> "$HISTORY_FILE"
+            history_append "$HISTORY_FILE" "$(printf '{"id":"%s","short":"%s","trigger":"%s","working_dir":"%s","started":"%s","status":"error","duration":%d}' \
+                "$full_id" "$short_id" "$trigger" "$working_dir" "$started" "$dur")"
             rm -f "${STATE_DIR}/locks/${trigger}.lock"
             echo "Process $pid was no longer running — marked as error."
             echo ""
@@ -48,6 +48,25 @@ config_max_parallel() {
     config_general "max_parallel" 2>/dev/null || echo "2"
 }
 
+# Get the default session timeout (duration string, empty = none)
+config_timeout() {
+    config_general "timeout" 2>/dev/null || true
+}
+
+# Get where webhook triggers are served: host:port, or a unix socket path
+config_webhook_listen() {
+    config_general "webhook_listen" 2>/dev/null || echo "127.0.0.1:7878"
+}
+
+# Get the history retention settings (empty = keep everything)
+config_retention_days() {
+    config_general "retention_days" 2>/dev/null || true
+}
+
+config_retention_per_trigger() {
+    config_general "retention_per_trigger" 2>/dev/null || true
+}
+
 # List all trigger names
 # Usage: config_list_triggers
 config_list_triggers() {
@@ -109,7 +128,7 @@ config_trigger_field() {
 
             if $found_trigger && [[ "$line" == "${field} "* || "$line" == "${field}="* ]]; then
                 local val="${line#*=}"
-                val="$(echo "$val" | sed 's/^[[:space:]]*//;s/[[:space:]]*$//;s/^"//;s/"$//')"
+                val="$(echo "$val" | sed "s/^[[:space:]]*//;s/[[:space:]]*\$//;s/^\([\"']\)\(.*\)\1\$/\2/")"
                 # Expand ~ to HOME
                 val="${val/#\~/$HOME}"
                 echo "$val"
@@ -153,7 +172,9 @@ config_trigger() {
             local key="${line%%=*}"
             key="$(echo "$key" | sed 's/^[[:space:]]*//;s/[[:space:]]*$//')"
             local val="${line#*=}"
-            val="$(echo "$val" | sed 's/^[[:space:]]*//;s/[[:space:]]*$//;s/^"//;s/"$//')"
+            # Basic "..." or literal '...' strings; a prompt template with
+            # quoted arguments goes in the latter
+            val="$(echo "$val" | sed "s/^[[:space:]]*//;s/[[:space:]]*\$//;s/^\([\"']\)\(.*\)\1\$/\2/")"
             val="${val/#\~/$HOME}"
 
             if [[ "$key" == "name" && "$val" == "$trigger_name" ]]; then
@@ -195,28 +216,61 @@ interval_to_minutes() {
     esac
 }
 
+# Convert a duration ("90s", "30m", "2h", "1h30m") to seconds.
+# A bare number is seconds. Returns 1 if the string is not a duration.
+duration_to_seconds() {
+    local d="$1" total=0
+    [[ "$d" =~ ^[0-9]+$ ]] && { echo "$d"; return 0; }
+    [[ "$d" =~ ^([0-9]+[hms])+$ ]] || return 1
+    while [[ "$d" =~ ^([0-9]+)([hms])(.*)$ ]]; do
+        case "${BASH_REMATCH[2]}" in
+            h) (( total += BASH_REMATCH[1] * 3600 )) ;;
+            m) (( total += BASH_REMATCH[1] * 60 )) ;;
+            s) (( total += BASH_REMATCH[1] )) ;;
+        esac
+        d="${BASH_REMATCH[3]}"
+    done
+    echo "$total"
+}
+
 # Output full config as JSON
 # Outputs a single JSON object with general settings and triggers array
 config_to_json() {
-    local state_dir max_parallel
+    local state_dir max_parallel timeout
     state_dir="$(config_state_dir)"
     max_parallel="$(config_max_parallel)"
-
-    printf '{"general":{"state_dir":"%s","max_parallel":%s},"triggers":[' \
-        "$state_dir" "$max_parallel"
+    timeout="$(config_timeout)"
+
+    printf '{"general":{"state_dir":"%s","max_parallel":%s' "$state_dir" "$max_parallel"
+    [[ -n "$timeout" ]] && printf ',"timeout":"%s"' "$timeout"
+    config_general "webhook_listen" &>/dev/null && printf ',"webhook_listen":"%s"' "$(config_webhook_listen)"
+    local budget_daily budget_monthly
+    budget_daily="$(config_general "budget_daily_usd" 2>/dev/null || true)"
+    budget_monthly="$(config_general "budget_monthly_usd" 2>/dev/null || true)"
+    [[ -n "$budget_daily" ]] && printf ',"budget_daily_usd":%s' "$budget_daily"
+    [[ -n "$budget_monthly" ]] && printf ',"budget_monthly_usd":%s' "$budget_monthly"
+    local retention_days retention_per_trigger
+    retention_days="$(config_retention_days)"
+    retention_per_trigger="$(config_retention_per_trigger)"
+    [[ -n "$retention_days" ]] && printf ',"retention_days":%s' "$retention_days"
+    [[ -n "$retention_per_trigger" ]] && printf ',"retention_per_trigger":%s' "$retention_per_trigger"
+    printf '},"triggers":['
 
     local first=true
     for name in $(config_list_triggers); do
         $first || printf ','
         first=false
 
-        local type skill prompt_text permissions working_dir cooldown check
+        local type skill prompt_text permissions working_dir cooldown check priority concurrency timeout
         type="$(config_trigger_field "$name" "type" || echo "")"
         skill="$(config_trigger_field "$name" "skill" || true)"
         prompt_text="$(config_trigger_field "$name" "prompt" || true)"
         permissions="$(config_trigger_field "$name" "permissions" || echo "default")"
         working_dir="$(config_trigger_field "$name" "working_dir" || true)"
         cooldown="$(config_trigger_field "$name" "cooldown" || true)"
+        priority="$(config_trigger_field "$name" "priority" || true)"
+        concurrency="$(config_trigger_field "$name" "concurrency" || true)"
+        timeout="$(config_trigger_field "$name" "timeout" || true)"
         check="$(config_trigger_field "$name" "check" || true)"
 
         printf '{"name":"%s","type":"%s","permissions":"%s"' "$name" "$type" "$permissions"
@@ -224,7 +278,15 @@ config_to_json() {
         [[ -n "$prompt_text" ]] && printf ',"prompt":"%s"' "$(echo "$prompt_text" | sed 's/"/\\"/g')"
         [[ -n "$working_dir" ]] && printf ',"working_dir":"%s"' "$working_dir"
         [[ -n "$cooldown" ]] && printf ',"cooldown":%s' "$cooldown"
+        [[ -n "$priority" ]] && printf ',"priority":%s' "$priority"
+        [[ -n "$concurrency" ]] && printf ',"concurrency":"%s"' "$concurrency"
+        [[ -n "$timeout" ]] && printf ',"timeout":"%s"' "$timeout"
         [[ -n "$check" ]] && printf ',"check":"%s"' "$(echo "$check" | sed 's/"/\\"/g')"
+        local check_format item_key
+        check_format="$(config_trigger_field "$name" "check_format" || true)"
+        item_key="$(config_trigger_field "$name" "item_key" || true)"
+        [[ -n "$check_format" ]] && printf ',"check_format":"%s"' "$check_format"
+        [[ -n "$item_key" ]] && printf ',"item_key":"%s"' "$item_key"
 
         if [[ "$type" == "timer" ]]; then
             local interval cron_expr
@@ -233,22 +295,39 @@ config_to_json() {
             [[ -n "$interval" ]] && printf ',"interval":"%s"' "$interval"
             [[ -n "$cron_expr" ]] && printf ',"cron":"%s"' "$cron_expr"
         elif [[ "$type" == "file" ]]; then
-            local watch pattern settle
+            local watch pattern settle batch_window
             watch="$(config_trigger_field "$name" "watch" || true)"
             pattern="$(config_trigger_field "$name" "pattern" || true)"
             settle="$(config_trigger_field "$name" "settle" || true)"
+            batch_window="$(config_trigger_field "$name" "batch_window" || true)"
             [[ -n "$watch" ]] && printf ',"watch":"%s"' "$watch"
             [[ -n "$pattern" ]] && printf ',"pattern":"%s"' "$pattern"
             [[ -n "$settle" ]] && printf ',"settle":%s' "$settle"
+            [[ -n "$batch_window" ]] && printf ',"batch_window":%s' "$batch_window"
         fi
 
-        local retry retry_max retry_delay
+        local retry retry_max retry_delay retry_backoff retry_delay_max
         retry="$(config_trigger_field "$name" "retry" || true)"
         retry_max="$(config_trigger_field "$name" "retry_max" || true)"
         retry_delay="$(config_trigger_field "$name" "retry_delay" || true)"
+        retry_backoff="$(config_trigger_field "$name" "retry_backoff" || true)"
+        retry_delay_max="$(config_trigger_field "$name" "retry_delay_max" || true)"
         [[ -n "$retry" ]] && printf ',"retry":"%s"' "$retry"
         [[ -n "$retry_max" ]] && printf ',"retry_max":%s' "$retry_max"
         [[ -n "$retry_delay" ]] && printf ',"retry_delay":%s' "$retry_delay"
+        [[ -n "$retry_backoff" ]] && printf ',"retry_backoff":"%s"' "$retry_backoff"
+        [[ -n "$retry_delay_max" ]] && printf ',"retry_delay_max":%s' "$retry_delay_max"
+
+        local on next
+        for on in success failure complete; do
+            next="$(config_trigger_field "$name" "on_$on" || true)"
+            [[ -n "$next" ]] && printf ',"on_%s":"%s"' "$on" "$next"
+        done
+
+        budget_daily="$(config_trigger_field "$name" "budget_daily_usd" || true)"
+        budget_monthly="$(config_trigger_field "$name" "budget_monthly_usd" || true)"
+        [[ -n "$budget_daily" ]] && printf ',"budget_daily_usd":%s' "$budget_daily"
+        [[ -n "$budget_monthly" ]] && printf ',"budget_monthly_usd":%s' "$budget_monthly"
 
         printf '}'
     done
@@ -256,6 +335,45 @@ config_to_json() {
     printf ']}\n'
 }
 
+# Set a key in [general] (empty trigger name) or in a trigger's block,
+# replacing an existing line or adding one. The value is written as given,
+# so quote strings yourself.
+# Usage: config_set_field <trigger|""> <key> <value>
+config_set_field() {
+    local trigger="$1" key="$2" value="$3" tmp
+    tmp="$(mktemp "${WORKMODE_CONFIG}.XXXXXX")"
+    # Pass 1 finds the target block and whether it already has the key;
+    # pass 2 rewrites. Block 0 is the preamble before any [section].
+    awk -v trigger="$trigger" -v key="$key" -v value="$value" '
+        function trim(l) { sub(/#.*/, "", l); gsub(/^[ \t]+|[ \t]+$/, "", l); return l }
+        function is_key(l, k) { return l ~ ("^" k "[ \t]*=") }
+        function strval(l) { sub(/^[^=]*=[ \t]*/, "", l); gsub(/^"|"$/, "", l); return l }
+        FNR == 1 { block = 0; pass++ }
+        { line = trim($0) }
+        line ~ /^\[/ { block++ }
+        pass == 1 {
+            if (trigger == "" && line == "[general]") target = block
+            if (trigger != "" && is_key(line, "name") && strval(line) == trigger) target = block
+            if (is_key(line, key)) has[block] = 1
+            next
+        }
+        !target { print; next }
+        block == target && is_key(line, key) { print key " = " value; next }
+        { print }
+        block == target && !has[target] && !done &&
+            ((trigger == "" && line == "[general]") || (trigger != "" && is_key(line, "name"))) {
+            print key " = " value
+            done = 1
+        }
+        END { if (!target) exit 1 }
+    ' "$WORKMODE_CONFIG" "$WORKMODE_CONFIG" > "$tmp" || {
+        rm -f "$tmp"
+        return 1
+    }
+    cat "$tmp" > "$WORKMODE_CONFIG"
+    rm -f "$tmp"
+}
+
 # Convert interval to cron schedule
 # "2h" → "0 */2 * * *", "15m" → "*/15 * * * *"
 interval_to_cron() {
@@ -0,0 +1,125 @@
+#!/usr/bin/env bash
+# cron.sh — Cron expressions → systemd OnCalendar for workmode-install
+# Accepts what the daemon's cron parser accepts, so a trigger fires at the
+# same times with or without systemd.
+
+# Parse one cron value, a number or a name from the given list (indexed
+# from min), and print it as a number.
+# Usage: cron_value <value> <min> [name...]
+cron_value() {
+    local value="$1" min="$2" i
+    shift 2
+    if [[ "$value" =~ ^[0-9]+$ ]]; then
+        echo "$(( 10#$value ))"
+        return 0
+    fi
+    for (( i = 1; i <= $#; i++ )); do
+        if [[ "${!i}" == "$value" ]]; then
+            echo "$(( min + i - 1 ))"
+            return 0
+        fi
+    done
+    return 1
+}
+
+# Expand a cron field into the comma-separated values it matches, or "*"
+# for all of them. Takes what the daemon's cron parser takes: numbers,
+# names, ranges ("1-5"), lists ("1,15") and steps ("*/15", "0-30/10").
+# Usage: cron_field_values <field> <min> <max> [name...]
+cron_field_values() {
+    local field="${1,,}" min="$2" max="$3" part rng step lo hi v values="" all=true
+    shift 3
+    local -a parts matched=()
+    IFS=',' read -ra parts <<< "$field"
+    (( ${#parts[@]} > 0 )) || return 1
+    for part in "${parts[@]}"; do
+        step=1
+        rng="$part"
+        if [[ "$part" == */* ]]; then
+            step="${part#*/}"
+            rng="${part%%/*}"
+            [[ "$step" =~ ^[0-9]+$ ]] && (( step > 0 )) || return 1
+        fi
+        case "$rng" in
+            "*") lo="$min"; hi="$max" ;;
+            *-*)
+                lo="$(cron_value "${rng%%-*}" "$min" "$@")" || return 1
+                hi="$(cron_value "${rng#*-}" "$min" "$@")" || return 1
+                ;;
+            *)
+                lo="$(cron_value "$rng" "$min" "$@")" || return 1
+                hi="$lo"
+                (( step > 1 )) && hi="$max"
+                ;;
+        esac
+        (( lo >= min && hi <= max && lo <= hi )) || return 1
+        for (( v = lo; v <= hi; v += step )); do
+            matched[v]=1
+        done
+    done
+    for (( v = min; v <= max; v++ )); do
+        if [[ -n "${matched[v]:-}" ]]; then
+            values+="${values:+,}$v"
+        else
+            all=false
+        fi
+    done
+    if $all; then echo "*"; else echo "$values"; fi
+}
+
+# Convert a 5-field cron expression (or @-macro) to systemd OnCalendar
+# lines, e.g. "45 8 * * mon-fri" → "OnCalendar=Mon,Tue,Wed,Thu,Fri *-*-* 08:45:00".
+# When both day fields are restricted, cron runs on days matching either,
+# while systemd needs both, so each gets its own OnCalendar line. Fails on
+# an expression the daemon would reject too.
+cron_to_oncalendar() {
+    local cron="$1" extra
+    local minute hour dom month dow
+
+    case "${cron,,}" in
+        @yearly|@annually)  cron="0 0 1 1 *" ;;
+        @monthly)           cron="0 0 1 * *" ;;
+        @weekly)            cron="0 0 * * 0" ;;
+        @daily|@midnight)   cron="0 0 * * *" ;;
+        @hourly)            cron="0 * * * *" ;;
+        @*)                 return 1 ;;
+    esac
+
+    read -r minute hour dom month dow extra <<< "$cron"
+    [[ -n "$dow" && -z "${extra:-}" ]] || return 1
+
+    local sd_minute sd_hour sd_dom sd_month sd_dow v
+    sd_minute="$(cron_field_values "$minute" 0 59)" || return 1
+    sd_hour="$(cron_field_values "$hour" 0 23)" || return 1
+    sd_dom="$(cron_field_values "$dom" 1 31)" || return 1
+    sd_month="$(cron_field_values "$month" 1 12 jan feb mar apr may jun jul aug sep oct nov dec)" || return 1
+    sd_dow="$(cron_field_values "$dow" 0 7 sun mon tue wed thu fri sat)" || return 1
+
+    # Two-digit hours and minutes
+    sd_minute="$(sed -E 's/\b([0-9])\b/0\1/g' <<< "$sd_minute")"
+    sd_hour="$(sed -E 's/\b([0-9])\b/0\1/g' <<< "$sd_hour")"
+
+    # Weekday names, with 7 as another Sunday
+    if [[ "$sd_dow" != "*" ]]; then
+        local -a day_names=(Sun Mon Tue Wed Thu Fri Sat Sun) days=()
+        local -A seen=()
+        IFS=',' read -ra dow_values <<< "$sd_dow"
+        for v in "${dow_values[@]}"; do
+            [[ -n "${seen[${day_names[v]}]:-}" ]] && continue
+            seen[${day_names[v]}]=1
+            days+=("${day_names[v]}")
+        done
+        sd_dow="$(IFS=,; echo "${days[*]}")"
+        (( ${#days[@]} == 7 )) && sd_dow="*"
+    fi
+
+    local time_part="${sd_hour}:${sd_minute}:00"
+    if [[ "$sd_dow" == "*" ]]; then
+        echo "OnCalendar=*-${sd_month}-${sd_dom} ${time_part}"
+    elif [[ "$sd_dom" == "*" || "$dom" == \** || "$dow" == \** ]]; then
+        echo "OnCalendar=${sd_dow} *-${sd_month}-${sd_dom} ${time_part}"
+    else
+        printf 'OnCalendar=%s *-%s-* %s\nOnCalendar=*-%s-%s %s\n' \
+            "$sd_dow" "$sd_month" "$time_part" "$sd_month" "$sd_dom" "$time_part"
+    fi
+}
@@ -0,0 +1,136 @@
+#!/usr/bin/env bash
+# history.sh — history.jsonl compaction and retention for workmode
+# history.jsonl gets a line per status change; compaction rewrites it with
+# only the final line of each session, in start order. Retention drops old
+# sessions along with their logs/<id>.* files and git snapshot refs.
+# Sessions started this month are kept whatever the retention, since monthly
+# budgets add up their cost. Dropped item runs of fan-out triggers leave their
+# item key in processed_items.tsv, so the item isn't picked up again.
+# Requires HISTORY_FILE and LOG_DIR, and snapshot.sh.
+
+# Sessions in these states are never pruned
+HISTORY_PROTECTED_STATUSES="running queued stuck"
+
+# Item runs in these states didn't process their item; a later fan-out retries it
+HISTORY_UNPROCESSED_STATUSES="error timeout skipped"
+
+# "<trigger>\t<item key>" for item runs history prune dropped
+history_items_file() {
+    echo "$(dirname "$HISTORY_FILE")/processed_items.tsv"
+}
+
+# Rewrite history keeping the final state of each session, and drop
+# sessions outside the retention window.
+# Usage: history_rewrite <days|""> <per_trigger|""> <dropped_ids_file> [--dry-run]
+# Prints "<lines before> <sessions kept>" and writes dropped sessions one per
+# line: the ID, then a tab and the working dir if the session has snapshots.
+history_rewrite() {
+    local days="$1" per_trigger="$2" dropped="$3" dry_run="${4:-}"
+    local cutoff="" month tmp before kept
+    : > "$dropped"
+    [[ -f "$HISTORY_FILE" ]] || { echo "0 0"; return; }
+    [[ -n "$days" && "$days" != "0" ]] && cutoff="$(date -d "-${days} days" +%Y-%m-%d)"
+    month="$(date +%Y-%m)"
+
+    tmp="$(mktemp "${HISTORY_FILE}.XXXXXX")"
+    (
+        # Appenders wait on this lock, so nothing is written between the
+        # read and the rename
+        flock -x 9 2>/dev/null || true
+        before="$(wc -l < "$HISTORY_FILE")"
+        awk -v cutoff="$cutoff" -v month="$month" -v per_trigger="${per_trigger:-0}" \
+            -v protected=" $HISTORY_PROTECTED_STATUSES " -v unprocessed=" $HISTORY_UNPROCESSED_STATUSES " \
+            -v dropped="$dropped" -v items="$tmp.items" '
+            function field(s, name,   re) {
+                re = "\"" name "\":\"[^\"]*\""
+                if (!match(s, re)) return ""
+                return substr(s, RSTART + length(name) + 4, RLENGTH - length(name) - 5)
+            }
+            # item_key as it is escaped in the JSON, which may include \"
+            function item_key(s) {
+                if (!match(s, /"item_key":"([^"\\]|\\.)*"/)) return ""
+                return substr(s, RSTART + 12, RLENGTH - 13)
+            }
+            {
+                id = field($0, "id")
+                if (id == "") next
+                if (!(id in last)) order[++n] = id
+                last[id] = $0
+            }
+            END {
+                # Walk newest first so per-trigger counts keep the latest runs
+                for (i = n; i >= 1; i--) {
+                    line = last[order[i]]
+                    count[field(line, "trigger")]++
+                    if (index(protected, " " field(line, "status") " ")) continue
+                    if (line ~ /"pinned":true/) continue
+                    if (substr(field(line, "started"), 1, 7) >= month) continue
+                    if (per_trigger > 0 && count[field(line, "trigger")] > per_trigger) drop[i] = 1
+                    if (cutoff != "" && substr(field(line, "started"), 1, 10) < cutoff) drop[i] = 1
+                }
+                for (i = 1; i <= n; i++) {
+                    if (!(i in drop)) { print last[order[i]]; continue }
+                    line = last[order[i]]
+                    if (line ~ /"git_tree_before"/) print order[i] "\t" field(line, "working_dir") > dropped
+                    else print order[i] > dropped
+                    key = item_key(line)
+                    if (key != "" && !index(unprocessed, " " field(line, "status") " "))
+                        print field(line, "trigger") "\t" key > items
+                }
+            }
+        ' "$HISTORY_FILE" > "$tmp"
+        kept="$(wc -l < "$tmp")"
+        if [[ "$dry_run" == "--dry-run" ]]; then
+            rm -f "$tmp"
+        else
+            chmod --reference="$HISTORY_FILE" "$tmp" 2>/dev/null || true
+            # Record the items before their runs leave the history
+            [[ -s "$tmp.items" ]] && cat "$tmp.items" >> "$(history_items_file)"
+            mv "$tmp" "$HISTORY_FILE"
+        fi
+        echo "$before $kept"
+    ) 9>>"${HISTORY_FILE}.lock"
+    rm -f "$tmp" "$tmp.items"
+}
+
+# Delete the log files of the dropped sessions history_rewrite listed
+# Prints "<files> <bytes>" removed.
+history_remove_logs() {
+    local ids_file="$1" dry_run="${2:-}"
+    local id file files=0 bytes=0 size
+    while IFS=$'\t' read -r id _; do
+        [[ -z "$id" ]] && continue
+        for file in "$LOG_DIR/$id".*; do
+            [[ -f "$file" ]] || continue
+            size="$(stat -c %s "$file" 2>/dev/null || echo 0)"
+            files=$(( files + 1 ))
+            bytes=$(( bytes + size ))
+            [[ "$dry_run" == "--dry-run" ]] || rm -f "$file"
+        done
+    done < "$ids_file"
+    echo "$files $bytes"
+}
+
+# Delete the refs that keep the dropped sessions' git snapshots, so git can
+# collect them
+history_remove_snapshots() {
+    local ids_file="$1" dry_run="${2:-}" id dir
+    [[ "$dry_run" == "--dry-run" ]] && return 0
+    while IFS=$'\t' read -r id dir; do
+        if [[ -n "$id" && -n "$dir" ]]; then
+            snapshot_drop "$dir" "$id"
+        fi
+    done < "$ids_file"
+}
+
+# Print a byte count as 512B / 12K / 3.4M
+format_bytes() {
+    local bytes="$1"
+    if (( bytes < 1024 )); then
+        echo "${bytes}B"
+    elif (( bytes < 1048576 )); then
+        echo "$(( bytes / 1024 ))K"
+    else
+        awk -v b="$bytes" 'BEGIN { printf "%.1fM\n", b / 1048576 }'
+    fi
+}
@@ -3,6 +3,11 @@
 
 NOTIFY_APP="workmode"
 
+# Containers and CI boxes have no notification daemon — make notify-send a no-op
+if ! command -v notify-send &>/dev/null; then
+    notify-send() { :; }
+fi
+
 notify_started() {
     local skill="$1"
     local trigger="$2"
@@ -32,6 +37,14 @@ notify_error() {
     notify-send -a "$NOTIFY_APP" -u critical "workmode" "❌ ${skill} failed — run: workmode session logs ${session_id}"
 }
 
+notify_timeout() {
+    local skill="$1"
+    local trigger="$2"
+    local session_id="$3"
+    local limit="$4"
+    notify-send -a "$NOTIFY_APP" -u critical "workmode" "⌛ ${skill} timed out after ${limit} — run: workmode session logs ${session_id}"
+}
+
 format_duration() {
     local seconds="$1"
     if (( seconds < 60 )); then
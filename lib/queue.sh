@@ -0,0 +1,81 @@
+#!/usr/bin/env bash
+# queue.sh — Persistent run queue for workmode
+# Runs that hit max_parallel are parked as one JSON file per entry in
+# $STATE_DIR/queue and started by workmode-run as slots free up.
+# Requires cli.sh (json_field*) and STATE_DIR.
+
+queue_dir() {
+    echo "$STATE_DIR/queue"
+}
+
+# Add an entry. Higher priority runs first; ties run oldest first.
+# Usage: queue_add <id> <short> <trigger> <priority> [file...]
+queue_add() {
+    local id="$1" short="$2" trigger="$3" priority="$4"
+    shift 4
+    local dir
+    dir="$(queue_dir)"
+    mkdir -p "$dir"
+
+    local fields
+    fields="$(json_field "id" "$id"),$(json_field "short" "$short"),$(json_field "trigger" "$trigger")"
+    fields+=",$(json_field_num "priority" "$priority"),$(json_field_num "queued" "$(date +%s)")"
+    (( $# > 0 )) && fields+=",$(json_field_array "files" "$@")"
+
+    # Write then rename so a draining runner never reads a partial entry
+    json_object "$fields" > "$dir/.${id}.tmp"
+    mv "$dir/.${id}.tmp" "$dir/${id}.json"
+}
+
+# Print the entry file for an ID, if queued
+queue_entry() {
+    local file
+    file="$(queue_dir)/$1.json"
+    [[ -f "$file" ]] && echo "$file"
+}
+
+# True if the trigger already has a queued run with no input files
+queue_has_trigger() {
+    local trigger="$1" file
+    for file in "$(queue_dir)"/*.json; do
+        [[ -f "$file" ]] || continue
+        grep -q "\"trigger\":\"${trigger}\"" "$file" && ! grep -q '"files":' "$file" && return 0
+    done
+    return 1
+}
+
+# Print the entry file that should run next, or nothing if the queue is empty
+queue_next() {
+    local file priority queued best="" best_priority=0 best_queued=0
+    for file in "$(queue_dir)"/*.json; do
+        [[ -f "$file" ]] || continue
+        priority="$(grep -oP '"priority":\K-?[0-9]+' "$file" || echo 0)"
+        queued="$(grep -oP '"queued":\K[0-9]+' "$file" || echo 0)"
+        if [[ -z "$best" ]] || (( priority > best_priority )) ||
+            (( priority == best_priority && queued < best_queued )); then
+            best="$file" best_priority="$priority" best_queued="$queued"
+        fi
+    done
+    [[ -n "$best" ]] && echo "$best"
+}
+
+# Print one input file per line from an entry
+queue_entry_files() {
+    local item
+    grep -oP '"files":\[\K.*(?=\])' "$1" 2>/dev/null |
+        grep -oP '"(?:[^"\\]|\\.)*"' |
+        while IFS= read -r item; do
+            item="${item#\"}"
+            item="${item%\"}"
+            item="${item//\\\"/\"}"
+            printf '%s\n' "${item//\\\\/\\}"
+        done
+}
+
+# Change an entry's priority in place
+# Usage: queue_set_priority <id> <priority>
+queue_set_priority() {
+    local file
+    file="$(queue_entry "$1")" || return 1
+    sed -i -E "s/\"priority\":-?[0-9]+/\"priority\":$2/" "$file"
+}
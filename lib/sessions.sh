@@ -15,6 +15,17 @@ else
     RED='' GREEN='' YELLOW='' BLUE='' DIM='' RESET='' BOLD=''
 fi
 
+# Append a line to history.jsonl. Holds the history lock so an append
+# never lands in a copy that `workmode history compact` is about to replace.
+# Usage: history_append <history_file> <line>
+history_append() {
+    local history_file="$1" line="$2"
+    {
+        flock -x 9 2>/dev/null || true
+        printf '%s\n' "$line" >> "$history_file"
+    } 9>>"${history_file}.lock"
+}
+
 # Extract JSON field value (simple, no jq dependency)
 parse_json_field() {
     local json="$1" field="$2"
@@ -69,6 +80,11 @@ status_icon() {
         error)     icon="❌"; text="err";     color="$RED" ;;
         stopped)   icon="⏹";  text="stopped"; color="$YELLOW" ;;
         killed)    icon="💀"; text="killed";  color="$RED" ;;
+        queued)    icon="⏳"; text="queued";  color="$DIM" ;;
+        cancelled) icon="⊘";  text="cancel";  color="$DIM" ;;
+        skipped)   icon="↷";  text="skipped"; color="$DIM" ;;
+        replaced)  icon="⇥";  text="repl";    color="$DIM" ;;
+        timeout)   icon="⌛"; text="timeout"; color="$RED" ;;
         *)         icon="?";  text="$1";      color="$RESET" ;;
     esac
     # Pad the visible text to 8 chars, then wrap in color codes
@@ -84,6 +100,11 @@ status_mark() {
         error)     echo "ERR" ;;
         stopped)   echo "STOP" ;;
         killed)    echo "KILL" ;;
+        queued)    echo "QUEUE" ;;
+        cancelled) echo "CANCEL" ;;
+        skipped)   echo "SKIP" ;;
+        replaced)  echo "REPL" ;;
+        timeout)   echo "TIMEOUT" ;;
         *)         echo "?" ;;
     esac
 }
@@ -182,6 +203,53 @@ format_session_log() {
     fi
 }
 
+# Print history fields (,"cost_usd":…) for the cost, token usage, turn
+# count and permission denials in a session log's final result event;
+# nothing if there is none.
+result_stats_fields() {
+    local log_file="$1" line value key fields=""
+    line="$(grep '"type":"result"' "$log_file" 2>/dev/null | tail -1 || true)"
+    [[ -n "$line" ]] || return 0
+
+    value="$(grep -oP '"total_cost_usd"\s*:\s*\K[0-9.eE+-]+' <<< "$line" | head -1 || true)"
+    [[ -n "$value" ]] && fields+=",\"cost_usd\":${value}"
+    for key in num_turns duration_api_ms input_tokens output_tokens \
+        cache_read_input_tokens cache_creation_input_tokens; do
+        value="$(grep -oP "\"${key}\"\\s*:\\s*\\K[0-9]+" <<< "$line" | head -1 || true)"
+        [[ -n "$value" ]] && fields+=",\"${key}\":${value}"
+    done
+    grep -qP '"is_error"\s*:\s*true' <<< "$line" && fields+=',"is_error":true'
+    value="$(grep -oP '"permission_denials"\s*:\s*\[\K.*' <<< "$line" | grep -o '"tool_use_id"' | wc -l || true)"
+    (( value > 0 )) && fields+=",\"denials\":${value}"
+    printf '%s' "$fields"
+}
+
+# Print the result text of a session log's final result event, or nothing
+# if there is none. Without jq, \uXXXX escapes are left as they are.
+result_text() {
+    local log_file="$1" text
+    [[ -f "$log_file" ]] || return 0
+    if command -v jq &>/dev/null; then
+        jq -Rnr '[inputs | fromjson? | select(.type == "result")] | last | .result // empty' "$log_file" 2>/dev/null || true
+        return 0
+    fi
+    text="$(grep '"type":"result"' "$log_file" 2>/dev/null | tail -1 | grep -oP '"result"\s*:\s*"\K(?:[^"\\]|\\.)*' | head -1 || true)"
+    text="${text//\\\"/\"}"
+    printf '%b\n' "$text"
+}
+
+# Format a token count compactly (950, 12.3k, 1.2M)
+format_tokens() {
+    local n="${1:-0}"
+    if (( n >= 1000000 )); then
+        printf '%d.%dM' $(( n / 1000000 )) $(( n % 1000000 / 100000 ))
+    elif (( n >= 1000 )); then
+        printf '%d.%dk' $(( n / 1000 )) $(( n % 1000 / 100 ))
+    else
+        printf '%d' "$n"
+    fi
+}
+
 # Extract a short summary from a session log file
 extract_summary() {
     local log_file="$1"
@@ -245,7 +313,7 @@ get_session_pid() {
 
 # Stop or kill a session by ID
 # Usage: terminate_session <id> <action> <history_file> <state_dir>
-#   action: "stop" (SIGTERM) or "kill" (SIGKILL)
+#   action: "stop" (SIGTERM), "kill" (SIGKILL), or "replace" (SIGTERM, by a newer run)
 terminate_session() {
     local lookup="$1"
     local action="$2"
@@ -282,9 +350,8 @@ terminate_session() {
         started="$(parse_json_field "$session_line" "started")"
         working_dir="$(parse_json_field "$session_line" "working_dir")"
         local duration=$(( $(date +%s) - $(date -d "$started" +%s 2>/dev/null || echo "$(date +%s)") ))
-        printf '{"id":"%s","short":"%s","trigger":"%s","working_dir":"%s","started":"%s","status":"error","duration":%d}\n' \
-            "$full_id" "$short_id" "$trigger" "$working_dir" "$started" "$duration" \
-            >> "$history_file"
+        history_append "$history_file" "$(printf '{"id":"%s","short":"%s","trigger":"%s","working_dir":"%s","started":"%s","status":"error","duration":%d}' \
+            "$full_id" "$short_id" "$trigger" "$working_dir" "$started" "$duration")"
         # Clean up stale lock if present
         rm -f "${state_dir}/locks/${trigger}.lock"
         echo "Process $pid is no longer alive. Marked session $short_id as error."
@@ -295,6 +362,7 @@ terminate_session() {
     case "$action" in
         stop) signal=15; label="SIGTERM"; new_status="stopped" ;;
         kill) signal=9;  label="SIGKILL"; new_status="killed" ;;
+        replace) signal=15; label="SIGTERM"; new_status="replaced" ;;
         *)    echo "Invalid action: $action" >&2; return 1 ;;
     esac
 
@@ -306,9 +374,8 @@ terminate_session() {
         started="$(parse_json_field "$session_line" "started")"
         working_dir="$(parse_json_field "$session_line" "working_dir")"
         local duration=$(( $(date +%s) - $(date -d "$started" +%s 2>/dev/null || echo "$(date +%s)") ))
-        printf '{"id":"%s","short":"%s","trigger":"%s","working_dir":"%s","started":"%s","status":"%s","duration":%d}\n' \
-            "$full_id" "$short_id" "$trigger" "$working_dir" "$started" "$new_status" "$duration" \
-            >> "$history_file"
+        history_append "$history_file" "$(printf '{"id":"%s","short":"%s","trigger":"%s","working_dir":"%s","started":"%s","status":"%s","duration":%d}' \
+            "$full_id" "$short_id" "$trigger" "$working_dir" "$started" "$new_status" "$duration")"
     else
         echo "Failed to send $label to process $pid." >&2
         return 1
@@ -0,0 +1,151 @@
+#!/usr/bin/env bash
+# snapshot.sh — Git snapshots of a run's working directory
+# workmode-run records HEAD, branch and dirty status of working_dir before
+# and after each run, the diff between the two as logs/<id>.diff and the
+# commits made in between as logs/<id>.commits. Snapshots are tree objects
+# written through a scratch index, so the repo's index and branches are
+# untouched; refs/workmode/<id>/{before,after} keep them from being
+# garbage collected until `workmode history prune` drops the session.
+# Requires cli.sh.
+
+# Write the whole working tree, untracked files included and ignored files
+# left out, as a tree object and print its ID. Fails outside a git repo.
+# Usage: snapshot_tree <dir>
+snapshot_tree() {
+    local dir="$1" index tmp tree
+    index="$(git -C "$dir" rev-parse --path-format=absolute --git-path index 2>/dev/null)" || return 1
+    tmp="$(mktemp)"
+    # Start from the real index so files whose stat is unchanged aren't
+    # hashed again
+    if [[ -f "$index" ]]; then
+        cp "$index" "$tmp"
+    else
+        rm -f "$tmp"
+    fi
+    if GIT_INDEX_FILE="$tmp" git -C "$dir" add -A >/dev/null 2>&1; then
+        tree="$(GIT_INDEX_FILE="$tmp" git -C "$dir" write-tree 2>/dev/null || true)"
+    fi
+    rm -f "$tmp"
+    [[ -n "${tree:-}" ]] || return 1
+    echo "$tree"
+}
+
+# Print history fields (,"git_head_before":…) for the state of a git
+# working dir: HEAD, branch, whether it differs from HEAD, and the snapshot
+# tree, which is kept as refs/workmode/<id>/<when>. Prints nothing outside
+# a git repo.
+# Usage: snapshot_fields <dir> <before|after> <session_id>
+snapshot_fields() {
+    local dir="$1" when="$2" id="$3" tree head branch fields=""
+    tree="$(snapshot_tree "$dir")" || return 0
+    git -C "$dir" update-ref "refs/workmode/$id/$when" "$tree" 2>/dev/null || true
+    head="$(git -C "$dir" rev-parse -q --verify HEAD 2>/dev/null || true)"
+    branch="$(git -C "$dir" symbolic-ref -q --short HEAD 2>/dev/null || true)"
+
+    fields+=",$(json_field "git_tree_$when" "$tree")"
+    [[ -n "$head" ]] && fields+=",$(json_field "git_head_$when" "$head")"
+    [[ -n "$branch" ]] && fields+=",$(json_field "git_branch_$when" "$branch")"
+    if [[ -z "$head" || "$tree" != "$(git -C "$dir" rev-parse -q --verify 'HEAD^{tree}' 2>/dev/null)" ]]; then
+        fields+=",\"git_dirty_$when\":true"
+    fi
+    printf '%s' "$fields"
+}
+
+# Write the diff between two snapshots to <diff_file> and the commits made
+# between two HEADs to <commits_file>, one "<hash> <subject>" line each,
+# then print history fields for the diff stat and commit count. Empty
+# diffs and commit lists leave no file.
+# Usage: snapshot_diff <dir> <tree_before> <tree_after> <head_before> <head_after> <diff_file> <commits_file>
+snapshot_diff() {
+    local dir="$1" before="$2" after="$3" head_before="$4" head_after="$5"
+    local diff_file="$6" commits_file="$7" stat files ins del commits=0
+
+    if [[ "$before" != "$after" ]]; then
+        git -C "$dir" diff --binary --no-color --no-ext-diff "$before" "$after" > "$diff_file" 2>/dev/null || true
+        [[ -s "$diff_file" ]] || rm -f "$diff_file"
+        # files, insertions, deletions; binary files count as changed only
+        stat="$(git -C "$dir" diff --numstat "$before" "$after" 2>/dev/null | awk '
+            { files++; if ($1 != "-") { ins += $1; del += $2 } }
+            END { printf "%d %d %d", files, ins, del }
+        ')"
+        read -r files ins del <<< "$stat"
+        (( files > 0 )) && printf ',"git_files_changed":%d,"git_insertions":%d,"git_deletions":%d' "$files" "$ins" "$del"
+    fi
+
+    if [[ -n "$head_after" && "$head_before" != "$head_after" ]]; then
+        local range="$head_after"
+        [[ -n "$head_before" ]] && range="$head_before..$head_after"
+        git -C "$dir" log --no-color --format='%h %s' "$range" > "$commits_file" 2>/dev/null || true
+        commits="$(wc -l < "$commits_file")"
+        (( commits > 0 )) || rm -f "$commits_file"
+        (( commits > 0 )) && printf ',"git_commits":%d' "$commits"
+    fi
+    return 0
+}
+
+# Delete the refs that keep a session's snapshots, once nothing needs them.
+# Usage: snapshot_drop <dir> <session_id>
+snapshot_drop() {
+    local dir="$1" id="$2" when
+    [[ -d "$dir" ]] || return 0
+    for when in before after; do
+        git -C "$dir" update-ref -d "refs/workmode/$id/$when" 2>/dev/null || true
+    done
+}
+
+# Check whether a run's saved diff can be taken back out of the working
+# tree, i.e. nothing changed since in a way that conflicts with it. Prints
+# git's complaints on failure.
+# Usage: snapshot_can_revert <dir> <diff_file>
+snapshot_can_revert() {
+    local dir="$1" diff_file="$2" top
+    top="$(git -C "$dir" rev-parse --show-toplevel 2>/dev/null)" || { echo "not a git working dir: $dir"; return 1; }
+    git -C "$top" apply -R --check "$diff_file" 2>&1
+}
+
+# Take a run's saved diff back out of the working tree. The index, HEAD and
+# any commits the run made are left alone.
+# Usage: snapshot_revert <dir> <diff_file>
+snapshot_revert() {
+    local dir="$1" diff_file="$2" top
+    top="$(git -C "$dir" rev-parse --show-toplevel 2>/dev/null)" || return 1
+    git -C "$top" apply -R "$diff_file"
+}
+
+# Check that snapshot trees are still in the repo. Sessions recorded before
+# snapshots were kept under refs/workmode may have lost theirs to git gc.
+# Usage: snapshot_available <dir> <tree>...
+snapshot_available() {
+    local dir="$1" tree
+    shift
+    for tree in "$@"; do
+        [[ -n "$tree" ]] && git -C "$dir" cat-file -e "${tree}^{tree}" 2>/dev/null || return 1
+    done
+}
+
+# Put every file that changed between two snapshots back to its content in
+# the first one, overwriting whatever is there now. Files the run created
+# are deleted.
+# Usage: snapshot_restore <dir> <tree_before> <tree_after>
+snapshot_restore() {
+    local dir="$1" before="$2" after="$3" top path mode
+    top="$(git -C "$dir" rev-parse --show-toplevel 2>/dev/null)" || return 1
+    while IFS= read -r -d '' path; do
+        mode="$(git -C "$top" ls-tree "$before" -- "$path" | awk '{ print $1 }')"
+        if [[ -z "$mode" ]]; then
+            rm -f "$top/$path"
+            continue
+        fi
+        [[ "$mode" == 160000 ]] && continue  # submodule
+        rm -f "$top/$path"
+        mkdir -p "$(dirname "$top/$path")"
+        if [[ "$mode" == 120000 ]]; then
+            ln -s "$(git -C "$top" cat-file blob "$before:$path")" "$top/$path"
+        else
+            git -C "$top" cat-file blob "$before:$path" > "$top/$path"
+            if [[ "$mode" == 100755 ]]; then
+                chmod +x "$top/$path"
+            fi
+        fi
+    done < <(git -C "$top" diff --no-renames --name-only -z "$before" "$after")
+}
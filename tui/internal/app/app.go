@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -13,7 +14,10 @@ import (
 
 	"github.com/olivoil/workmode/tui/internal/backend"
 	"github.com/olivoil/workmode/tui/internal/ui"
+	"github.com/olivoil/workmode/tui/internal/views/approvals"
+	"github.com/olivoil/workmode/tui/internal/views/changes"
 	"github.com/olivoil/workmode/tui/internal/views/command"
+	"github.com/olivoil/workmode/tui/internal/views/diffview"
 	"github.com/olivoil/workmode/tui/internal/views/logview"
 	"github.com/olivoil/workmode/tui/internal/views/sessions"
 	"github.com/olivoil/workmode/tui/internal/views/triggers"
@@ -58,6 +62,9 @@ const (
 	viewSessions viewMode = iota
 	viewTriggers
 	viewLog
+	viewChanges
+	viewDiff
+	viewApprovals
 	viewCommand
 )
 
@@ -76,25 +83,39 @@ type model struct {
 	send    func(tea.Msg)
 
 	status   backend.Status
-	sessions []backend.Session
-	triggers []backend.Trigger
-
-	sessionsView sessions.Model
-	triggersView triggers.Model
-	commandView  command.Model
-	logView      logview.Model
+	daemon      backend.DaemonState
+	sessions    []backend.Session
+	triggers    []backend.Trigger
+	timerStarts map[string]time.Time // active systemd timers' start times
+
+	// History is read incrementally, one read at a time so diffs apply in
+	// order; a change seen mid-read queues one more.
+	loadingSessions bool
+	sessionsStale   bool
+
+	sessionsView  sessions.Model
+	triggersView  triggers.Model
+	commandView   command.Model
+	logView       logview.Model
+	changesView   changes.Model
+	diffView      diffview.Model
+	approvalsView approvals.Model
 }
 
 func newModel() model {
 	client := backend.NewClient(CLIBinary, AppName)
 	return model{
-		mode:         viewSessions,
-		keys:         DefaultKeyMap(),
-		client:       client,
-		sessionsView: sessions.New(),
-		triggersView: triggers.New(),
-		commandView:  command.New(),
-		logView:      logview.New(),
+		mode:            viewSessions,
+		keys:            DefaultKeyMap(),
+		client:          client,
+		loadingSessions: true, // started by Init
+		sessionsView:    sessions.New(),
+		triggersView:    triggers.New(),
+		commandView:     command.New(),
+		logView:         logview.New(),
+		changesView:     changes.New(),
+		diffView:        diffview.New(),
+		approvalsView:   approvals.New(),
 	}
 }
 
@@ -104,6 +125,7 @@ func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		m.loadSessions,
 		m.loadTriggers,
+		m.loadDaemon,
 		m.tickStatusNow(),
 	)
 }
@@ -130,23 +152,36 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case SessionsLoadedMsg:
-		if msg.Err == nil {
-			m.sessions = msg.Sessions
-			m.sessionsView.SetSessions(msg.Sessions)
-			m.triggersView.SetSessions(msg.Sessions)
-			ids := make([]string, len(msg.Sessions))
-			for i, s := range msg.Sessions {
+		m.loadingSessions = false
+		var next tea.Cmd
+		if m.sessionsStale {
+			next = m.refreshSessions()
+		}
+		if msg.Err != nil || msg.Diff.Empty() {
+			return m, next
+		}
+		m.sessions = msg.Diff.Apply(m.sessions)
+		m.sessionsView.ApplyDiff(msg.Diff, m.sessions)
+		m.triggersView.SetSessions(m.sessions)
+		if msg.Diff.Reset || len(msg.Diff.Added) > 0 {
+			ids := make([]string, len(m.sessions))
+			for i, s := range m.sessions {
 				ids[i] = s.Short
 			}
 			m.commandView.SetSessionIDs(ids)
-			m.status = backend.DeriveStats(m.status, m.sessions)
-			return m, m.loadSelectedPreview()
 		}
-		return m, nil
+		m.status = backend.DeriveStats(m.status, m.sessions)
+		if m.mode == viewApprovals {
+			next = tea.Batch(next, m.loadApprovals())
+		}
+		return m, tea.Batch(next, m.loadSelectedPreview())
 
 	case TriggersLoadedMsg:
 		if msg.Err == nil {
 			m.triggers = msg.Triggers
+			m.timerStarts = msg.TimerStarts
+			m.triggersView.SetBudget(msg.Budget, msg.Overrides)
+			m.triggersView.SetSchedulerStarts(m.daemonStarted(), m.timerStarts)
 			m.triggersView.SetTriggers(msg.Triggers)
 			names := make([]string, len(msg.Triggers))
 			for i, t := range msg.Triggers {
@@ -156,13 +191,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case DaemonLoadedMsg:
+		if msg.Err == nil {
+			m.daemon = msg.State
+			m.triggersView.SetErrors(msg.State.Errors)
+			m.triggersView.SetSchedulerStarts(m.daemonStarted(), m.timerStarts)
+		}
+		return m, nil
+
 	case LogLoadedMsg:
 		if msg.Err != nil {
 			return m, nil
 		}
 		switch m.mode {
 		case viewSessions:
-			m.sessionsView.SetPreview(msg.ShortID, msg.Events)
+			m.sessionsView.SetPrompt(msg.Prompt)
+			m.sessionsView.AppendPreview(msg.ShortID, msg.Events, msg.Added)
 		case viewLog:
 			if !m.logView.Active() {
 				// First load — find the session and show.
@@ -173,20 +217,54 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			} else {
-				m.logView.UpdateLog(msg.Events)
+				m.logView.AppendLog(msg.Events, msg.Added)
+			}
+		case viewChanges:
+			if !m.changesView.Active() {
+				if s := m.sessionByShort(msg.ShortID); s != nil {
+					m.changesView.Show(s, msg.Events)
+				}
+			} else {
+				m.changesView.SetEvents(msg.Events)
+			}
+		}
+		return m, nil
+
+	case DiffLoadedMsg:
+		if m.mode != viewDiff {
+			return m, nil
+		}
+		s := m.sessionByShort(msg.ShortID)
+		if msg.Err != nil || s == nil {
+			m.mode = m.prevMode
+			m.focusCurrentView()
+			if msg.Err != nil {
+				m.commandView.SetError(msg.Err)
 			}
+			return m, nil
 		}
+		m.diffView.Show(s, msg.Diff, msg.Commits)
+		return m, nil
+
+	case ApprovalsLoadedMsg:
+		m.approvalsView.SetApprovals(msg.Approvals)
 		return m, nil
 
 	case backend.WatchMsg:
 		switch msg.Kind {
 		case backend.WatchHistory:
-			return m, m.loadSessions
+			return m, m.refreshSessions()
+		case backend.WatchDaemon:
+			return m, m.loadDaemon
 		case backend.WatchLog:
 			if m.mode == viewLog {
 				if s := m.logView.Session(); s != nil {
 					return m, m.loadPreview(s.Short)
 				}
+			} else if m.mode == viewChanges {
+				if s := m.changesView.Session(); s != nil {
+					return m, m.loadPreview(s.Short)
+				}
 			} else {
 				return m, m.loadSelectedPreview()
 			}
@@ -194,6 +272,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case StatusTickMsg:
+		m.triggersView.RefreshNextRuns()
 		return m, tea.Batch(m.loadStatus, m.tickStatus())
 
 	case command.ExecuteMsg:
@@ -206,12 +285,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case ActionResultMsg:
+		if m.mode == viewApprovals {
+			m.approvalsView.SetStatus(actionStatus(msg))
+		}
 		if msg.Err != nil {
 			m.commandView.SetError(msg.Err)
 		} else {
 			m.commandView.SetResult(msg.Output)
 		}
-		return m, tea.Batch(m.loadStatus, m.loadSessions, m.loadTriggers)
+		return m, tea.Batch(m.loadStatus, m.refreshSessions(), m.loadTriggers)
 
 	case command.NLStreamMsg, command.NLDoneMsg:
 		var cmd tea.Cmd
@@ -220,7 +302,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case ResumeExitMsg:
 		// Claude --resume exited. Refresh everything.
-		return m, tea.Batch(m.loadStatus, m.loadSessions, m.loadTriggers)
+		return m, tea.Batch(m.loadStatus, m.refreshSessions(), m.loadTriggers)
 
 	case tea.KeyPressMsg:
 		// If command line has focus, let it handle keys first.
@@ -243,8 +325,16 @@ func (m model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 
 	// Log view has its own key handling.
 	if m.mode == viewLog {
+		if m.logView.Typing() && key != "ctrl+c" {
+			var cmd tea.Cmd
+			m.logView, cmd = m.logView.Update(msg)
+			return m, cmd
+		}
 		switch key {
 		case "q", "esc":
+			if key == "esc" && m.logView.ClearSearch() {
+				return m, nil
+			}
 			m.logView.Hide()
 			if m.watcher != nil {
 				m.watcher.WatchLog("")
@@ -259,12 +349,61 @@ func (m model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 				return m, m.resumeSession(*s)
 			}
 			return m, nil
+		case "ctrl+e":
+			// The command line lives in the list views; go back to run it there.
+			if s := m.logView.Session(); s != nil {
+				sess := *s
+				m.logView.Hide()
+				if m.watcher != nil {
+					m.watcher.WatchLog("")
+				}
+				m.mode = m.prevMode
+				return m, m.openCommand(exportCommand(sess))
+			}
+			return m, nil
 		}
 		var cmd tea.Cmd
 		m.logView, cmd = m.logView.Update(msg)
 		return m, cmd
 	}
 
+	if m.mode == viewChanges {
+		switch key {
+		case "q", "esc":
+			m.changesView.Hide()
+			if m.watcher != nil {
+				m.watcher.WatchLog("")
+			}
+			m.mode = m.prevMode
+			m.focusCurrentView()
+			return m, nil
+		case "ctrl+c":
+			return m, tea.Quit
+		}
+		var cmd tea.Cmd
+		m.changesView, cmd = m.changesView.Update(msg)
+		return m, cmd
+	}
+
+	if m.mode == viewDiff {
+		switch key {
+		case "q", "esc":
+			m.diffView.Hide()
+			m.mode = m.prevMode
+			m.focusCurrentView()
+			return m, nil
+		case "ctrl+c":
+			return m, tea.Quit
+		}
+		var cmd tea.Cmd
+		m.diffView, cmd = m.diffView.Update(msg)
+		return m, cmd
+	}
+
+	if m.mode == viewApprovals {
+		return m.handleApprovalsKey(msg)
+	}
+
 	switch key {
 	case "q", "ctrl+c":
 		return m, tea.Quit
@@ -293,24 +432,154 @@ func (m model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "x", "+", "-":
+		// Queue actions: cancel or reprioritize the selected queued run.
+		if m.mode == viewSessions {
+			if s := m.sessionsView.SelectedSession(); s != nil && s.Status == "queued" {
+				switch key {
+				case "x":
+					return m, m.executeCommand([]string{"session", "cancel", s.Short})
+				case "+":
+					return m, m.executeCommand([]string{"session", "priority", s.Short, "+1"})
+				case "-":
+					return m, m.executeCommand([]string{"session", "priority", s.Short, "-1"})
+				}
+			}
+		}
+
+	case "p":
+		// Toggle whether history pruning keeps the selected session.
+		if m.mode == viewSessions {
+			if s := m.sessionsView.SelectedSession(); s != nil && s.Status != "running" && s.Status != "queued" {
+				action := "pin"
+				if s.Pinned {
+					action = "unpin"
+				}
+				return m, m.executeCommand([]string{"session", action, s.Short})
+			}
+		}
+
+	case "c":
+		// Files the selected session wrote or edited, as diffs.
+		if m.mode == viewSessions {
+			if s := m.sessionsView.SelectedSession(); s != nil {
+				m.prevMode = m.mode
+				m.mode = viewChanges
+				m.sessionsView.Blur()
+				if m.watcher != nil && s.Status == "running" {
+					m.watcher.WatchLog(s.ID)
+				}
+				return m, m.openLogView(*s)
+			}
+		}
+
+	case "D":
+		// The git diff of the selected session's working dir.
+		if m.mode == viewSessions {
+			if s := m.sessionsView.SelectedSession(); s != nil && s.TreeAfter != "" {
+				m.prevMode = m.mode
+				m.mode = viewDiff
+				m.sessionsView.Blur()
+				return m, m.loadDiff(*s)
+			}
+		}
+
+	case "u":
+		// Undo the selected session's changes; the command line asks first.
+		if m.mode == viewSessions {
+			if s := m.sessionsView.SelectedSession(); s != nil && s.CanRevert() {
+				return m, m.openCommand("session revert " + s.Short)
+			}
+		}
+
+	case "ctrl+e":
+		if m.mode == viewSessions {
+			if s := m.sessionsView.SelectedSession(); s != nil {
+				return m, m.openCommand(exportCommand(*s))
+			}
+		}
+
+	case "a":
+		// Tool calls stuck sessions were refused, waiting for approval.
+		if m.mode == viewSessions || m.mode == viewTriggers {
+			m.prevMode = m.mode
+			m.mode = viewApprovals
+			m.sessionsView.Blur()
+			m.triggersView.Blur()
+			m.approvalsView.Show()
+			return m, m.loadApprovals()
+		}
+
+	case "b", "B":
+		// Budget actions: override once, or raise the limit that is hit.
+		if m.mode == viewTriggers {
+			if t := m.triggersView.SelectedTrigger(); t != nil {
+				if p, paused := m.triggersView.BudgetPause(t.Name); paused {
+					if key == "b" {
+						return m, m.executeCommand([]string{"budget", "override", t.Name})
+					}
+					return m, m.openCommand(raiseBudgetCommand(t.Name, p))
+				}
+			}
+		}
+
 	case "?":
 		m.showHelp = !m.showHelp
 		return m, nil
 
 	case "/":
-		m.prevMode = m.mode
-		m.sessionsView.Blur()
-		m.triggersView.Blur()
-		cmd := m.commandView.Focus()
-		return m, cmd
+		return m, m.openCommand("")
 
 	case "ctrl+l":
-		return m, tea.Batch(m.loadStatus, m.loadSessions, m.loadTriggers)
+		return m, tea.Batch(m.loadStatus, m.refreshSessions(), m.loadTriggers)
 	}
 
 	return m.updateActiveView(msg)
 }
 
+// handleApprovalsKey handles keys in the approvals view: a allows the
+// selected call and resumes its session, A allows all of the session's
+// refused calls, x denies them.
+func (m model) handleApprovalsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.approvalsView.Hide()
+		m.mode = m.prevMode
+		m.focusCurrentView()
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "a", "A":
+		a := m.approvalsView.Selected()
+		if a == nil || a.Denial.ToolName == "" {
+			return m, nil
+		}
+		rules := []string{a.Denial.Rule()}
+		if msg.String() == "A" {
+			rules = m.approvalsView.Rules(a.Session.ID)
+		}
+		args := []string{"session", "approve", a.Session.Short}
+		for _, r := range rules {
+			args = append(args, "--allow", r)
+		}
+		m.approvalsView.SetStatus(fmt.Sprintf("resuming %s with %d tool call%s allowed…", a.Session.Short, len(rules), plural(len(rules))))
+		return m, m.executeCommand(args)
+	case "x":
+		if a := m.approvalsView.Selected(); a != nil {
+			return m, m.executeCommand([]string{"session", "deny", a.Session.Short})
+		}
+		return m, nil
+	case "ctrl+r":
+		if a := m.approvalsView.Selected(); a != nil {
+			return m, m.resumeSession(a.Session)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.approvalsView, cmd = m.approvalsView.Update(msg)
+	return m, cmd
+}
+
 func (m model) handleEnter() (tea.Model, tea.Cmd) {
 	switch m.mode {
 	case viewSessions:
@@ -370,6 +639,18 @@ func (m model) updateActiveView(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.logView, cmd = m.logView.Update(msg)
 		return m, cmd
+	case viewChanges:
+		var cmd tea.Cmd
+		m.changesView, cmd = m.changesView.Update(msg)
+		return m, cmd
+	case viewDiff:
+		var cmd tea.Cmd
+		m.diffView, cmd = m.diffView.Update(msg)
+		return m, cmd
+	case viewApprovals:
+		var cmd tea.Cmd
+		m.approvalsView, cmd = m.approvalsView.Update(msg)
+		return m, cmd
 	}
 	return m, nil
 }
@@ -395,7 +676,34 @@ func (m model) View() tea.View {
 	if m.mode == viewLog {
 		b.WriteString(m.logView.View())
 		b.WriteByte('\n')
-		b.WriteString(ui.StyleDim.Render(" esc back  │  ctrl+r resume  │  j/k scroll  │  q quit"))
+		b.WriteString(ui.StyleDim.Render(" esc back  │  ctrl+r resume  │  j/k scroll  │  / search  │  tab tool  │  enter fold  │  e/c open/close all  │  ctrl+e export  │  q quit"))
+		v.SetContent(b.String())
+		return v
+	}
+
+	// Full-screen changes view.
+	if m.mode == viewChanges {
+		b.WriteString(m.changesView.View())
+		b.WriteByte('\n')
+		b.WriteString(ui.StyleDim.Render(" esc back  │  j/k scroll  │  tab/shift+tab next/prev file  │  q quit"))
+		v.SetContent(b.String())
+		return v
+	}
+
+	// Full-screen diff view.
+	if m.mode == viewDiff {
+		b.WriteString(m.diffView.View())
+		b.WriteByte('\n')
+		b.WriteString(ui.StyleDim.Render(" esc back  │  j/k scroll  │  tab/shift+tab next/prev file  │  q quit"))
+		v.SetContent(b.String())
+		return v
+	}
+
+	// Full-screen approvals view.
+	if m.mode == viewApprovals {
+		b.WriteString(m.approvalsView.View())
+		b.WriteByte('\n')
+		b.WriteString(ui.StyleDim.Render(" esc back  │  j/k select  │  a approve  │  A approve all for session  │  x deny  │  ctrl+r resume  │  q quit"))
 		v.SetContent(b.String())
 		return v
 	}
@@ -460,9 +768,38 @@ func (m *model) renderHeader() string {
 		watcherStr = ui.StyleDim.Render("watcher: ") + ui.StyleInactive.Render("down")
 	}
 
+	if m.status.Webhooks {
+		watcherStr += ui.StyleDim.Render("   webhooks: ") + ui.StyleActive.Render("up")
+	}
+
+	if m.status.Daemon {
+		watcherStr += ui.StyleDim.Render("   daemon: ") + ui.StyleActive.Render("up")
+		if n := len(m.daemon.Errors); n > 0 {
+			watcherStr += " " + ui.StyleError.Render(fmt.Sprintf("(%d error%s)", n, plural(n)))
+		}
+	}
+
+	queued := ""
+	if m.status.Queued > 0 {
+		queued = fmt.Sprintf("   queued: %d", m.status.Queued)
+	}
+	if m.status.Approvals > 0 {
+		queued += fmt.Sprintf("   approvals: %d", m.status.Approvals)
+	}
+	usage := ""
+	if m.status.CostToday > 0 || m.status.TokensToday > 0 {
+		usage = fmt.Sprintf(" (%s", ui.FormatCost(m.status.CostToday))
+		if m.status.CostToday > 0 && m.status.TokensToday > 0 {
+			usage += ", "
+		}
+		if m.status.TokensToday > 0 {
+			usage += ui.FormatTokens(m.status.TokensToday) + " tok"
+		}
+		usage += ")"
+	}
 	stats := ui.StyleDim.Render(fmt.Sprintf(
-		"timers: %d/%d   running: %d   today: %d",
-		m.status.Timers, m.status.Triggers, m.status.Running, m.status.Today,
+		"timers: %d/%d   running: %d%s   today: %d%s",
+		m.status.Timers, m.status.Triggers, m.status.Running, queued, m.status.Today, usage,
 	))
 
 	sep := ui.StyleDim.Render("   ")
@@ -482,6 +819,9 @@ func (m *model) renderHelpLine() string {
 	case viewTriggers:
 		parts = []string{"↑↓ navigate", "enter run", "tab sessions", "/ command", "q quit"}
 	}
+	if m.status.Approvals > 0 {
+		parts = append(parts[:len(parts)-1], "a approvals", parts[len(parts)-1])
+	}
 	return ui.StyleDim.Render(" " + strings.Join(parts, "  │  "))
 }
 
@@ -499,6 +839,31 @@ func (m *model) renderHelpOverlay() string {
     ctrl+r          Resume session in Claude
     ctrl+s          Stop running session
     ctrl+k          Kill running session
+    x               Cancel queued run
+    + / -           Raise / lower queued run priority
+    p               Pin / unpin (pinned sessions survive pruning)
+    ctrl+e          Export transcript (.md, .html or .json path)
+    c               Files changed, with each edit as a diff
+    D               Git diff of the working dir over the run
+    u               Revert the run's changes to the working dir
+    a               Approvals: tool calls stuck sessions were refused
+
+  Approvals
+    a               Allow the selected call and resume its session
+    A               Allow all of the session's refused calls and resume
+    x               Deny: leave the session stopped
+
+  Log View
+    tab / shift+tab Select next / previous tool call
+    enter           Open / close the selected tool call
+    e / c           Open / close all tool calls
+    /               Search (regexp; case-insensitive unless it has capitals)
+    n / N           Next / previous match
+    esc             Clear the search
+
+  Trigger Actions
+    b               Run a budget-paused trigger once anyway
+    B               Raise the budget limit it is paused on
 
   Command Line
     /               Open command line
@@ -530,6 +895,9 @@ func (m *model) layoutViews() {
 	m.triggersView.SetSize(m.width, viewHeight)
 	m.commandView.SetSize(m.width, viewHeight)
 	m.logView.SetSize(m.width, m.height-1) // full height minus help line
+	m.changesView.SetSize(m.width, m.height-1)
+	m.diffView.SetSize(m.width, m.height-1)
+	m.approvalsView.SetSize(m.width, m.height-1)
 }
 
 // --- Commands ---
@@ -540,13 +908,99 @@ func (m *model) loadStatus() tea.Msg {
 }
 
 func (m *model) loadSessions() tea.Msg {
-	sessions, err := m.client.ReadSessions()
-	return SessionsLoadedMsg{Sessions: sessions, Err: err}
+	diff, err := m.client.ReadSessionChanges()
+	return SessionsLoadedMsg{Diff: diff, Err: err}
+}
+
+// refreshSessions starts an incremental history read, or marks one as
+// needed if a read is already in flight.
+func (m *model) refreshSessions() tea.Cmd {
+	if m.loadingSessions {
+		m.sessionsStale = true
+		return nil
+	}
+	m.loadingSessions = true
+	m.sessionsStale = false
+	return m.loadSessions
+}
+
+func (m *model) loadDaemon() tea.Msg {
+	st, err := m.client.ReadDaemonState()
+	return DaemonLoadedMsg{State: st, Err: err}
 }
 
 func (m *model) loadTriggers() tea.Msg {
 	triggers, err := m.client.ReadTriggers()
-	return TriggersLoadedMsg{Triggers: triggers, Err: err}
+	if err != nil {
+		return TriggersLoadedMsg{Err: err}
+	}
+	budget, _ := m.client.ReadBudget()
+	var timers []string
+	for _, t := range triggers {
+		if t.Type == "timer" && t.Cron == "" {
+			timers = append(timers, t.Name)
+		}
+	}
+	return TriggersLoadedMsg{
+		Triggers:    triggers,
+		Budget:      budget,
+		Overrides:   m.client.BudgetOverrides(),
+		TimerStarts: m.client.TimerStarts(timers),
+	}
+}
+
+// daemonStarted returns when the running `workmode daemon` started, or
+// zero when none is running.
+func (m *model) daemonStarted() time.Time {
+	t, _ := time.Parse(time.RFC3339, m.daemon.Started)
+	return t
+}
+
+// openCommand focuses the command line, optionally with text pre-filled.
+func (m *model) openCommand(text string) tea.Cmd {
+	m.prevMode = m.mode
+	m.sessionsView.Blur()
+	m.triggersView.Blur()
+	if text != "" {
+		return m.commandView.FocusWith(text)
+	}
+	return m.commandView.Focus()
+}
+
+// raiseBudgetCommand suggests doubling the limit a trigger is paused on;
+// the user can edit the amount before running it.
+// exportCommand is the command line offered to export a session, with a
+// default path to adjust. The extension picks the format.
+func exportCommand(s backend.Session) string {
+	return fmt.Sprintf("session export %s --output ~/%s.md", s.Short, s.Short)
+}
+
+func raiseBudgetCommand(trigger string, p backend.BudgetPause) string {
+	target := trigger
+	if p.Global {
+		target = "--global"
+	}
+	limit := strconv.FormatFloat(p.Limit*2, 'f', -1, 64)
+	return fmt.Sprintf("budget set %s --%s %s", target, p.Period, limit)
+}
+
+// actionStatus summarizes a CLI action's outcome in one line.
+func actionStatus(msg ActionResultMsg) string {
+	if msg.Err != nil {
+		return msg.Err.Error()
+	}
+	out := strings.TrimSpace(msg.Output)
+	if i := strings.LastIndexByte(out, '\n'); i >= 0 {
+		out = out[i+1:]
+	}
+	return out
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
 }
 
 func (m *model) sessionByShort(shortID string) *backend.Session {
@@ -566,8 +1020,9 @@ func (m *model) loadPreview(shortID string) tea.Cmd {
 	fullID := s.ID
 	client := m.client
 	return func() tea.Msg {
-		events, err := client.ReadLog(fullID)
-		return LogLoadedMsg{ShortID: shortID, Events: events, Err: err}
+		log, err := client.TailLog(fullID)
+		prompt, _ := client.ReadPrompt(fullID)
+		return LogLoadedMsg{ShortID: shortID, Events: log.Events, Added: log.Added, Prompt: prompt, Err: err}
 	}
 }
 
@@ -584,8 +1039,26 @@ func (m *model) openLogView(s backend.Session) tea.Cmd {
 	fullID := s.ID
 	short := s.Short
 	return func() tea.Msg {
-		events, _ := client.ReadLog(fullID)
-		return LogLoadedMsg{ShortID: short, Events: events}
+		log, _ := client.TailLog(fullID)
+		return LogLoadedMsg{ShortID: short, Events: log.Events, Added: log.Added}
+	}
+}
+
+func (m *model) loadDiff(s backend.Session) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		diff, commits, err := client.ReadDiff(s.ID)
+		return DiffLoadedMsg{ShortID: s.Short, Diff: diff, Commits: commits, Err: err}
+	}
+}
+
+// loadApprovals reads the refused tool calls of the sessions that need
+// approval from their logs.
+func (m *model) loadApprovals() tea.Cmd {
+	client := m.client
+	sessions := append([]backend.Session(nil), m.sessions...)
+	return func() tea.Msg {
+		return ApprovalsLoadedMsg{Approvals: client.ReadApprovals(sessions)}
 	}
 }
 
@@ -1,6 +1,10 @@
 package app
 
-import "github.com/olivoil/workmode/tui/internal/backend"
+import (
+	"time"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+)
 
 // StatusLoadedMsg is sent when status data is fetched.
 type StatusLoadedMsg struct {
@@ -8,25 +12,52 @@ type StatusLoadedMsg struct {
 	Err    error
 }
 
-// SessionsLoadedMsg is sent when session data is loaded (from file or CLI).
+// SessionsLoadedMsg is sent when new history lines have been read.
 type SessionsLoadedMsg struct {
-	Sessions []backend.Session
-	Err      error
+	Diff backend.SessionDiff
+	Err  error
 }
 
 // TriggersLoadedMsg is sent when trigger data is fetched.
 type TriggersLoadedMsg struct {
-	Triggers []backend.Trigger
-	Err      error
+	Triggers  []backend.Trigger
+	Budget    backend.Budget  // global spend limits
+	Overrides map[string]bool // triggers with a one-time budget override
+	// TimerStarts holds when each active systemd timer was started.
+	TimerStarts map[string]time.Time
+	Err         error
+}
+
+// DaemonLoadedMsg is sent when daemon.json is read.
+type DaemonLoadedMsg struct {
+	State backend.DaemonState
+	Err   error
 }
 
-// LogLoadedMsg is sent when a session's log is loaded.
+// LogLoadedMsg is sent when a session's log is read. Events holds the whole
+// log; the last Added of them are new since the previous read.
 type LogLoadedMsg struct {
 	ShortID string
 	Events  []backend.StreamEvent
+	Added   int
+	Prompt  string // the prompt the session was sent, if saved
 	Err     error
 }
 
+// DiffLoadedMsg is sent when a session's saved git diff is read.
+type DiffLoadedMsg struct {
+	ShortID string
+	Diff    string
+	Commits []string
+	Err     error
+}
+
+// ApprovalsLoadedMsg is sent when the refused tool calls of sessions that
+// need approval are read.
+type ApprovalsLoadedMsg struct {
+	Approvals []backend.Approval
+}
+
 // ActionResultMsg is sent when a CLI action completes.
 type ActionResultMsg struct {
 	Output string
@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Approval is a tool call a session was refused permission for, waiting for
+// the user to allow it (`workmode session approve`, which resumes the
+// session with the call allowed) or deny it (`workmode session deny`).
+type Approval struct {
+	Session Session
+	Denial  PermissionDenial
+}
+
+// ReadApprovals lists the refused tool calls of the sessions that need
+// approval, in session order. A session whose log names no refused call is
+// listed once with an empty Denial: it can only be resumed interactively.
+func (c *Client) ReadApprovals(sessions []Session) []Approval {
+	var approvals []Approval
+	for _, s := range sessions {
+		if !s.NeedsApproval() {
+			continue
+		}
+		events, _ := c.ReadLog(s.ID)
+		denials := ExtractDenials(events)
+		if len(denials) == 0 {
+			approvals = append(approvals, Approval{Session: s})
+			continue
+		}
+		for _, d := range denials {
+			approvals = append(approvals, Approval{Session: s, Denial: d})
+		}
+	}
+	return approvals
+}
+
+// ExtractDenials returns the tool calls a run was refused permission for:
+// the permission_denials of its result event or, when the run ended without
+// one, the calls whose result is a permission refusal. Calls refused more
+// than once with the same input are listed once.
+func ExtractDenials(events []StreamEvent) []PermissionDenial {
+	var denials []PermissionDenial
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Type == EventResult {
+			denials = events[i].PermissionDenials
+			break
+		}
+	}
+	if len(denials) == 0 {
+		for _, e := range NewTranscript(events).Entries {
+			if e.IsTool() && e.Result != nil && e.Result.IsError &&
+				strings.Contains(e.Result.Content.Text(), "requested permissions") {
+				denials = append(denials, PermissionDenial{ToolName: e.Tool.Name, ToolUseID: e.Tool.ID, ToolInput: e.Tool.Input})
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	unique := denials[:0:0]
+	for _, d := range denials {
+		if key := d.Rule() + "\x00" + string(d.ToolInput); !seen[key] {
+			seen[key] = true
+			unique = append(unique, d)
+		}
+	}
+	return unique
+}
+
+// Rule returns the --allowedTools rule that allows the refused call and
+// little else: the exact command for Bash, the file for tools that read or
+// edit one, the domain for WebFetch, and the tool itself otherwise.
+func (d PermissionDenial) Rule() string {
+	in := d.Args()
+	switch d.ToolName {
+	case "Bash":
+		if in.Command != "" {
+			return "Bash(" + in.Command + ")"
+		}
+	case "Edit", "MultiEdit", "Write", "NotebookEdit":
+		// Edit rules cover every tool that edits files.
+		if p := firstNonEmpty(in.FilePath, in.NotebookPath); p != "" {
+			return "Edit(" + pathRule(p) + ")"
+		}
+	case "Read":
+		if in.FilePath != "" {
+			return "Read(" + pathRule(in.FilePath) + ")"
+		}
+	case "WebFetch":
+		if u, err := url.Parse(in.URL); err == nil && u.Hostname() != "" {
+			return "WebFetch(domain:" + u.Hostname() + ")"
+		}
+	}
+	return d.ToolName
+}
+
+// Target returns what the refused call wanted to run or touch: the command,
+// file, URL or query.
+func (d PermissionDenial) Target() string {
+	in := d.Args()
+	return firstNonEmpty(in.Command, in.FilePath, in.NotebookPath, in.URL, in.Query, in.Pattern, in.Path)
+}
+
+// pathRule writes a path for a permission rule, where an absolute path
+// starts with "//" (a single "/" is relative to the settings file).
+func pathRule(p string) string {
+	if strings.HasPrefix(p, "/") {
+		return "/" + p
+	}
+	return p
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
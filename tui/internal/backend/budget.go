@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Budget is a spend limit in USD. Zero means no limit.
+type Budget struct {
+	DailyUSD   float64 `json:"budget_daily_usd,omitempty"`
+	MonthlyUSD float64 `json:"budget_monthly_usd,omitempty"`
+}
+
+// BudgetPause describes the budget that keeps a trigger from launching.
+type BudgetPause struct {
+	Global bool   // the [general] budget, not the trigger's own
+	Period string // "daily" or "monthly"
+	Spent  float64
+	Limit  float64
+}
+
+func (p BudgetPause) String() string {
+	scope := "trigger"
+	if p.Global {
+		scope = "global"
+	}
+	return fmt.Sprintf("%s %s budget $%.2f reached ($%.2f spent)", scope, p.Period, p.Limit, p.Spent)
+}
+
+// Spend sums the recorded cost of sessions started at or after since.
+// An empty trigger sums all sessions.
+func Spend(sessions []Session, trigger string, since time.Time) float64 {
+	var total float64
+	for _, s := range sessions {
+		if trigger != "" && s.Trigger != trigger {
+			continue
+		}
+		if t := s.StartedTime(); !t.IsZero() && !t.Before(since) {
+			total += s.CostUSD
+		}
+	}
+	return total
+}
+
+// CheckBudget returns the first budget t is over: the trigger's daily and
+// monthly limits, then the global ones.
+func CheckBudget(global Budget, t Trigger, sessions []Session, now time.Time) (BudgetPause, bool) {
+	now = now.Local()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	checks := []struct {
+		global  bool
+		period  string
+		limit   float64
+		since   time.Time
+		trigger string
+	}{
+		{false, "daily", t.Budget.DailyUSD, day, t.Name},
+		{false, "monthly", t.Budget.MonthlyUSD, month, t.Name},
+		{true, "daily", global.DailyUSD, day, ""},
+		{true, "monthly", global.MonthlyUSD, month, ""},
+	}
+	for _, c := range checks {
+		if c.limit <= 0 {
+			continue
+		}
+		if spent := Spend(sessions, c.trigger, c.since); spent >= c.limit {
+			return BudgetPause{Global: c.global, Period: c.period, Spent: spent, Limit: c.limit}, true
+		}
+	}
+	return BudgetPause{}, false
+}
+
+// BudgetOverridePath returns the file that lets the next run of a trigger
+// start despite its budget (written by `workmode budget override`).
+func BudgetOverridePath(stateDir, trigger string) string {
+	return filepath.Join(stateDir, "budget", trigger+".override")
+}
+
+// BudgetOverrides returns the triggers with a pending one-time override.
+func (c *Client) BudgetOverrides() map[string]bool {
+	overrides := make(map[string]bool)
+	matches, _ := filepath.Glob(BudgetOverridePath(c.stateDir, "*"))
+	for _, p := range matches {
+		overrides[strings.TrimSuffix(filepath.Base(p), ".override")] = true
+	}
+	return overrides
+}
+
+// ReadBudget reads the global spend limits from [general].
+func (c *Client) ReadBudget() (Budget, error) {
+	cfg, err := ReadConfigFile(c.configPath)
+	if err != nil {
+		return Budget{}, err
+	}
+	return cfg.General.Budget, nil
+}
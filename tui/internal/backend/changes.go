@@ -0,0 +1,111 @@
+package backend
+
+// Tools that change files.
+const (
+	ToolWrite        = "Write"
+	ToolEdit         = "Edit"
+	ToolMultiEdit    = "MultiEdit"
+	ToolNotebookEdit = "NotebookEdit"
+)
+
+// FileEdit is one change a tool call made, or tried to make, to a file.
+type FileEdit struct {
+	Tool      string // ToolWrite, ToolEdit, ToolMultiEdit or ToolNotebookEdit
+	ToolUseID string
+	OldString string // replaced text (Edit, MultiEdit)
+	NewString string // replacement, or the new cell source for NotebookEdit
+	Content   string // the whole file (Write)
+	Cell      string // NotebookEdit: cell ID and edit mode, e.g. "abc (insert)"
+	Failed    bool   // the tool reported an error, so the file is likely unchanged
+	Pending   bool   // no result yet
+}
+
+// FileChange is a file a session wrote or edited, with its edits in order.
+type FileChange struct {
+	Path  string
+	Edits []FileEdit
+}
+
+// Op describes what happened to the file: "write" when a Write replaced it
+// and no edit followed, "edit" otherwise. Failed edits are ignored unless
+// every edit failed, which is "failed".
+func (f FileChange) Op() string {
+	op := "failed"
+	for _, e := range f.Edits {
+		switch {
+		case e.Failed:
+		case e.Tool == ToolWrite:
+			op = "write"
+		default:
+			op = "edit"
+		}
+	}
+	return op
+}
+
+// Applied counts the edits that didn't fail.
+func (f FileChange) Applied() int {
+	n := 0
+	for _, e := range f.Edits {
+		if !e.Failed {
+			n++
+		}
+	}
+	return n
+}
+
+// ExtractFileChanges lists the files a session's Write, Edit, MultiEdit
+// and NotebookEdit calls touched, in the order they were first touched.
+// Subagent calls are included. Files changed by other means (Bash, MCP
+// tools) can't be seen in the log and are not listed.
+func ExtractFileChanges(events []StreamEvent) []FileChange {
+	var changes []FileChange
+	index := make(map[string]int)
+	for _, e := range NewTranscript(events).Entries {
+		if !e.IsTool() {
+			continue
+		}
+		edits, path := fileEdits(*e.Tool)
+		if path == "" {
+			continue
+		}
+		for i := range edits {
+			edits[i].ToolUseID = e.Tool.ID
+			edits[i].Failed = e.Result != nil && e.Result.IsError
+			edits[i].Pending = e.Result == nil
+		}
+		i, ok := index[path]
+		if !ok {
+			i = len(changes)
+			index[path] = i
+			changes = append(changes, FileChange{Path: path})
+		}
+		changes[i].Edits = append(changes[i].Edits, edits...)
+	}
+	return changes
+}
+
+// fileEdits returns the edits of a file-changing tool call and the file's
+// path; other calls return no path.
+func fileEdits(t ToolUseBlock) ([]FileEdit, string) {
+	in := t.Args()
+	switch t.Name {
+	case ToolWrite:
+		return []FileEdit{{Tool: t.Name, Content: in.Content}}, in.FilePath
+	case ToolEdit:
+		return []FileEdit{{Tool: t.Name, OldString: in.OldString, NewString: in.NewString}}, in.FilePath
+	case ToolMultiEdit:
+		edits := make([]FileEdit, 0, len(in.Edits))
+		for _, s := range in.Edits {
+			edits = append(edits, FileEdit{Tool: t.Name, OldString: s.OldString, NewString: s.NewString})
+		}
+		return edits, in.FilePath
+	case ToolNotebookEdit:
+		cell := in.CellID
+		if in.EditMode != "" && in.EditMode != "replace" {
+			cell += " (" + in.EditMode + ")"
+		}
+		return []FileEdit{{Tool: t.Name, NewString: in.NewSource, Cell: cell}}, in.NotebookPath
+	}
+	return nil, ""
+}
@@ -7,9 +7,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
-
 )
 
 // Client wraps the workmode CLI and direct file access.
@@ -18,6 +19,8 @@ type Client struct {
 	appName    string
 	stateDir   string // e.g. ~/.local/share/workmode
 	configPath string
+	history    *HistoryReader
+	logs       *LogTailer
 }
 
 // NewClient creates a client. cliBinary is the CLI command name (e.g. "workmode").
@@ -31,18 +34,23 @@ func NewClient(cliBinary, appName string) *Client {
 
 	// Read state_dir directly from TOML config (no subprocess).
 	if cfg, err := ReadConfigFile(c.configPath); err == nil && cfg.General.StateDir != "" {
-		c.stateDir = expandHome(cfg.General.StateDir)
+		c.stateDir = ExpandHome(cfg.General.StateDir)
 	}
 	if c.stateDir == "" {
 		home, _ := os.UserHomeDir()
 		c.stateDir = filepath.Join(home, ".local", "share", appName)
 	}
+	c.history = NewHistoryReader(c.HistoryPath())
+	c.logs = NewLogTailer()
 	return c
 }
 
 // StateDir returns the resolved state directory.
 func (c *Client) StateDir() string { return c.stateDir }
 
+// ConfigPath returns the path to config.toml.
+func (c *Client) ConfigPath() string { return c.configPath }
+
 // HistoryPath returns the path to history.jsonl.
 func (c *Client) HistoryPath() string {
 	return filepath.Join(c.stateDir, "history.jsonl")
@@ -53,6 +61,33 @@ func (c *Client) LogPath(sessionID string) string {
 	return filepath.Join(c.stateDir, "logs", sessionID+".log")
 }
 
+// DiffPath returns the path of the diff workmode-run saved of a session's
+// changes to its git working dir.
+func (c *Client) DiffPath(sessionID string) string {
+	return filepath.Join(c.stateDir, "logs", sessionID+".diff")
+}
+
+// ReadDiff returns a session's saved diff and the commits made during the
+// run ("<hash> <subject>", newest first). Either is empty when the run
+// changed nothing, or its working dir is not in a git repo.
+func (c *Client) ReadDiff(sessionID string) (diff string, commits []string, err error) {
+	data, err := os.ReadFile(c.DiffPath(sessionID))
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, err
+	}
+	diff = string(data)
+	data, err = os.ReadFile(filepath.Join(c.stateDir, "logs", sessionID+".commits"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, err
+	}
+	for _, l := range strings.Split(string(data), "\n") {
+		if l != "" {
+			commits = append(commits, l)
+		}
+	}
+	return diff, commits, nil
+}
+
 // --- Direct file access (fast, used for live updates) ---
 
 // ReadSessions reads and deduplicates sessions from history.jsonl.
@@ -60,11 +95,87 @@ func (c *Client) ReadSessions() ([]Session, error) {
 	return ParseSessionsFromFile(c.HistoryPath())
 }
 
+// ReadSessionChanges returns the changes to history.jsonl since the previous
+// call. The first call (and any after the file is replaced) returns every
+// session with Reset set.
+func (c *Client) ReadSessionChanges() (SessionDiff, error) {
+	return c.history.Read()
+}
+
 // ReadLog reads and parses a session's log file by full session ID.
 func (c *Client) ReadLog(sessionID string) ([]StreamEvent, error) {
 	return ParseLogFile(c.LogPath(sessionID))
 }
 
+// TailLog reads a session's log incrementally: only lines appended since
+// the previous TailLog of the same session are parsed.
+func (c *Client) TailLog(sessionID string) (LogRead, error) {
+	return c.logs.Read(c.LogPath(sessionID))
+}
+
+// DaemonStatePath returns the path of the status file written by `workmode daemon`.
+func DaemonStatePath(stateDir string) string {
+	return filepath.Join(stateDir, "daemon.json")
+}
+
+// ReadDaemonState reads daemon.json. A missing file or a dead daemon
+// process yields an empty state.
+func (c *Client) ReadDaemonState() (DaemonState, error) {
+	data, err := os.ReadFile(DaemonStatePath(c.stateDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DaemonState{}, nil
+		}
+		return DaemonState{}, err
+	}
+	var st DaemonState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return DaemonState{}, fmt.Errorf("parse daemon state: %w", err)
+	}
+	if st.PID <= 0 || syscall.Kill(st.PID, 0) == syscall.ESRCH {
+		return DaemonState{}, nil
+	}
+	return st, nil
+}
+
+// TimerUnitPrefix starts the names of the systemd units `workmode install`
+// writes for each trigger.
+const TimerUnitPrefix = "workmode-trigger-"
+
+// TimerStarts returns when the systemd timers of the named triggers were
+// started, for those that are active. An interval timer first fires one
+// interval after that (OnActiveSec). Without systemd it returns nil.
+func (c *Client) TimerStarts(names []string) map[string]time.Time {
+	if len(names) == 0 {
+		return nil
+	}
+	args := []string{"--user", "show", "--timestamp=unix", "--property=Id,ActiveState,ActiveEnterTimestamp"}
+	for _, name := range names {
+		args = append(args, TimerUnitPrefix+name+".timer")
+	}
+	out, err := exec.Command("systemctl", args...).Output()
+	if err != nil {
+		return nil
+	}
+	starts := make(map[string]time.Time)
+	for _, unit := range strings.Split(string(out), "\n\n") {
+		props := make(map[string]string)
+		for _, line := range strings.Split(unit, "\n") {
+			if k, v, ok := strings.Cut(line, "="); ok {
+				props[k] = v
+			}
+		}
+		name, ok := strings.CutPrefix(strings.TrimSuffix(props["Id"], ".timer"), TimerUnitPrefix)
+		if !ok || props["ActiveState"] != "active" {
+			continue
+		}
+		if sec, err := strconv.ParseInt(strings.TrimPrefix(props["ActiveEnterTimestamp"], "@"), 10, 64); err == nil && sec > 0 {
+			starts[name] = time.Unix(sec, 0)
+		}
+	}
+	return starts
+}
+
 // --- Direct file access (config) ---
 
 // ReadTriggers reads triggers directly from the TOML config file.
@@ -167,7 +278,7 @@ func (c *Client) RunCommand(args ...string) ([]byte, error) {
 // ResumeCmd returns an *exec.Cmd for `claude --resume <sessionID>` in the correct working dir.
 func (c *Client) ResumeCmd(s Session) *exec.Cmd {
 	cmd := exec.Command("claude", "--resume", s.SessionID)
-	dir := expandHome(s.WorkingDir)
+	dir := ExpandHome(s.WorkingDir)
 	if dir != "" {
 		cmd.Dir = dir
 	}
@@ -209,7 +320,8 @@ func parseNDJSON[T any](data []byte) ([]T, error) {
 	return result, nil
 }
 
-func expandHome(path string) string {
+// ExpandHome expands a leading "~/" to the user's home directory.
+func ExpandHome(path string) string {
 	if strings.HasPrefix(path, "~/") {
 		home, _ := os.UserHomeDir()
 		return filepath.Join(home, path[2:])
@@ -217,16 +329,28 @@ func expandHome(path string) string {
 	return path
 }
 
-// DeriveStats computes Running and Today counts from sessions and merges into Status.
+// DeriveStats computes Running, Queued and Today counts and today's cost and
+// token totals from sessions and merges them into Status.
 func DeriveStats(status Status, sessions []Session) Status {
 	now := time.Now()
 	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// status may already carry counts from an earlier call.
+	status.Running, status.Queued, status.Today, status.Approvals = 0, 0, 0, 0
+	status.CostToday, status.TokensToday = 0, 0
 	for _, s := range sessions {
-		if s.Status == "running" {
+		switch s.Status {
+		case "running":
 			status.Running++
+		case "queued":
+			status.Queued++
+		}
+		if s.NeedsApproval() {
+			status.Approvals++
 		}
 		if t := s.StartedTime(); !t.IsZero() && t.After(todayStart) {
 			status.Today++
+			status.CostToday += s.CostUSD
+			status.TokensToday += s.TotalTokens()
 		}
 	}
 	return status
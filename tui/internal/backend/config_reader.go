@@ -12,28 +12,49 @@ type tomlConfig struct {
 	General struct {
 		StateDir    string `toml:"state_dir"`
 		MaxParallel int    `toml:"max_parallel"`
+		Timeout     string `toml:"timeout"`
+		// WebhookListen is a host:port or a unix socket path.
+		WebhookListen string `toml:"webhook_listen"`
+
+		BudgetDailyUSD   float64 `toml:"budget_daily_usd"`
+		BudgetMonthlyUSD float64 `toml:"budget_monthly_usd"`
 	} `toml:"general"`
 	Trigger []tomlTrigger `toml:"trigger"`
 }
 
 // tomlTrigger mirrors a [[trigger]] entry in the TOML config.
 type tomlTrigger struct {
-	Name        string `toml:"name"`
-	Type        string `toml:"type"`
-	Permissions string `toml:"permissions"`
-	Skill       string `toml:"skill"`
-	Prompt      string `toml:"prompt"`
-	WorkingDir  string `toml:"working_dir"`
-	Cooldown    int    `toml:"cooldown"`
-	Check       string `toml:"check"`
-	Interval    string `toml:"interval"`
-	Cron        string `toml:"cron"`
-	Watch       string `toml:"watch"`
-	Pattern     string `toml:"pattern"`
-	Settle      int    `toml:"settle"`
-	Retry       string `toml:"retry"`
-	RetryMax    int    `toml:"retry_max"`
-	RetryDelay  int    `toml:"retry_delay"`
+	Name          string `toml:"name"`
+	Type          string `toml:"type"`
+	Permissions   string `toml:"permissions"`
+	Skill         string `toml:"skill"`
+	Prompt        string `toml:"prompt"`
+	WorkingDir    string `toml:"working_dir"`
+	Cooldown      int    `toml:"cooldown"`
+	Check         string `toml:"check"`
+	CheckFormat   string `toml:"check_format"`
+	ItemKey       string `toml:"item_key"`
+	Priority      int    `toml:"priority"`
+	Concurrency   string `toml:"concurrency"`
+	Timeout       string `toml:"timeout"`
+	Interval      string `toml:"interval"`
+	Cron          string `toml:"cron"`
+	Watch         string `toml:"watch"`
+	Pattern       string `toml:"pattern"`
+	Settle        int    `toml:"settle"`
+	BatchWindow   int    `toml:"batch_window"`
+	Retry         string `toml:"retry"`
+	RetryMax      int    `toml:"retry_max"`
+	RetryDelay    int    `toml:"retry_delay"`
+	RetryBackoff  string `toml:"retry_backoff"`
+	RetryDelayMax int    `toml:"retry_delay_max"`
+	OnSuccess     string `toml:"on_success"`
+	OnFailure     string `toml:"on_failure"`
+	OnComplete    string `toml:"on_complete"`
+	Secret        string `toml:"secret"`
+
+	BudgetDailyUSD   float64 `toml:"budget_daily_usd"`
+	BudgetMonthlyUSD float64 `toml:"budget_monthly_usd"`
 }
 
 // DefaultConfigPath returns the default config file path.
@@ -59,25 +80,41 @@ func ReadConfigFile(path string) (Config, error) {
 	cfg := Config{}
 	cfg.General.StateDir = tc.General.StateDir
 	cfg.General.MaxParallel = tc.General.MaxParallel
+	cfg.General.Timeout = tc.General.Timeout
+	cfg.General.WebhookListen = tc.General.WebhookListen
+	cfg.General.Budget = Budget{DailyUSD: tc.General.BudgetDailyUSD, MonthlyUSD: tc.General.BudgetMonthlyUSD}
 
 	for _, t := range tc.Trigger {
 		cfg.Triggers = append(cfg.Triggers, Trigger{
-			Name:        t.Name,
-			Type:        t.Type,
-			Permissions: t.Permissions,
-			Skill:       t.Skill,
-			Prompt:      t.Prompt,
-			WorkingDir:  t.WorkingDir,
-			Cooldown:    t.Cooldown,
-			Check:       t.Check,
-			Interval:    t.Interval,
-			Cron:        t.Cron,
-			Watch:       t.Watch,
-			Pattern:     t.Pattern,
-			Settle:      t.Settle,
-			Retry:       t.Retry,
-			RetryMax:    t.RetryMax,
-			RetryDelay:  t.RetryDelay,
+			Name:          t.Name,
+			Type:          t.Type,
+			Permissions:   t.Permissions,
+			Skill:         t.Skill,
+			Prompt:        t.Prompt,
+			WorkingDir:    t.WorkingDir,
+			Cooldown:      t.Cooldown,
+			Check:         t.Check,
+			CheckFormat:   t.CheckFormat,
+			ItemKey:       t.ItemKey,
+			Priority:      t.Priority,
+			Concurrency:   t.Concurrency,
+			Timeout:       t.Timeout,
+			Interval:      t.Interval,
+			Cron:          t.Cron,
+			Watch:         t.Watch,
+			Pattern:       t.Pattern,
+			Settle:        t.Settle,
+			BatchWindow:   t.BatchWindow,
+			Retry:         t.Retry,
+			RetryMax:      t.RetryMax,
+			RetryDelay:    t.RetryDelay,
+			RetryBackoff:  t.RetryBackoff,
+			RetryDelayMax: t.RetryDelayMax,
+			OnSuccess:     t.OnSuccess,
+			OnFailure:     t.OnFailure,
+			OnComplete:    t.OnComplete,
+			Secret:        t.Secret,
+			Budget:        Budget{DailyUSD: t.BudgetDailyUSD, MonthlyUSD: t.BudgetMonthlyUSD},
 		})
 	}
 	return cfg, nil
@@ -0,0 +1,258 @@
+package backend
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // America/New_York for the DST cases
+)
+
+// at is a time in UTC, "2006-01-02 15:04".
+func at(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func formatTimes(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, v := range times {
+		out[i] = v.Format("2006-01-02 15:04 Mon")
+	}
+	return out
+}
+
+func TestCronNext(t *testing.T) {
+	// 2026-10-17 is a Saturday.
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want []string
+	}{
+		{"every minute", "* * * * *", "2026-10-17 10:07", []string{
+			"2026-10-17 10:08 Sat", "2026-10-17 10:09 Sat",
+		}},
+		{"steps", "*/15 * * * *", "2026-10-17 10:07", []string{
+			"2026-10-17 10:15 Sat", "2026-10-17 10:30 Sat", "2026-10-17 10:45 Sat", "2026-10-17 11:00 Sat",
+		}},
+		{"stepped range", "0 1-5/2 * * *", "2026-10-17 02:00", []string{
+			"2026-10-17 03:00 Sat", "2026-10-17 05:00 Sat", "2026-10-18 01:00 Sun",
+		}},
+		{"step from a value", "0 20/2 * * *", "2026-10-17 19:00", []string{
+			"2026-10-17 20:00 Sat", "2026-10-17 22:00 Sat", "2026-10-18 20:00 Sun",
+		}},
+		{"list", "0,30 9 * * *", "2026-10-17 09:00", []string{
+			"2026-10-17 09:30 Sat", "2026-10-18 09:00 Sun", "2026-10-18 09:30 Sun",
+		}},
+		{"weekday names", "45 8 * * MON-FRI", "2026-10-16 09:00", []string{
+			"2026-10-19 08:45 Mon", "2026-10-20 08:45 Tue", "2026-10-21 08:45 Wed",
+			"2026-10-22 08:45 Thu", "2026-10-23 08:45 Fri", "2026-10-26 08:45 Mon",
+		}},
+		{"month names in either case", "0 0 1 JAN,jul *", "2026-10-17 00:00", []string{
+			"2027-01-01 00:00 Fri", "2027-07-01 00:00 Thu", "2028-01-01 00:00 Sat",
+		}},
+		{"sunday as 0", "0 12 * * 0", "2026-10-17 00:00", []string{
+			"2026-10-18 12:00 Sun", "2026-10-25 12:00 Sun",
+		}},
+		{"sunday as 7", "0 12 * * 7", "2026-10-17 00:00", []string{
+			"2026-10-18 12:00 Sun", "2026-10-25 12:00 Sun",
+		}},
+		{"range ending in 7", "0 12 * * fri-7", "2026-10-16 13:00", []string{
+			"2026-10-17 12:00 Sat", "2026-10-18 12:00 Sun", "2026-10-23 12:00 Fri",
+		}},
+		// Both day fields restricted: either one matching is enough.
+		{"day of month or weekday", "0 0 13 * fri", "2026-11-01 00:00", []string{
+			"2026-11-06 00:00 Fri", "2026-11-13 00:00 Fri", "2026-11-20 00:00 Fri",
+			"2026-11-27 00:00 Fri", "2026-12-04 00:00 Fri", "2026-12-11 00:00 Fri",
+			"2026-12-13 00:00 Sun",
+		}},
+		// A day field starting with * restricts nothing on its own: both must match.
+		{"stepped day of month and weekday", "0 0 */10 * mon", "2026-10-17 00:00", []string{
+			"2026-12-21 00:00 Mon", "2027-01-11 00:00 Mon", "2027-02-01 00:00 Mon",
+		}},
+		{"month rollover", "0 0 31 * *", "2026-09-30 12:00", []string{
+			"2026-10-31 00:00 Sat", "2026-12-31 00:00 Thu", "2027-01-31 00:00 Sun",
+		}},
+		{"year rollover", "59 23 31 12 *", "2026-12-31 23:59", []string{
+			"2027-12-31 23:59 Fri",
+		}},
+		{"leap day", "0 0 29 2 *", "2026-10-17 00:00", []string{
+			"2028-02-29 00:00 Tue", "2032-02-29 00:00 Sun",
+		}},
+		{"yearly", "@yearly", "2026-10-17 00:00", []string{"2027-01-01 00:00 Fri"}},
+		{"monthly", "@monthly", "2026-10-17 00:00", []string{"2026-11-01 00:00 Sun"}},
+		{"weekly", "@weekly", "2026-10-17 00:00", []string{"2026-10-18 00:00 Sun"}},
+		{"daily", "@DAILY", "2026-10-17 00:00", []string{"2026-10-18 00:00 Sun"}},
+		{"hourly", "@hourly", "2026-10-17 10:00", []string{"2026-10-17 11:00 Sat"}},
+		// Never: the search gives up instead of looping.
+		{"february 31st", "0 0 31 2 *", "2026-10-17 00:00", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := len(tt.want)
+			if n == 0 {
+				n = 1
+			}
+			if got := formatTimes(c.NextN(at(t, tt.from), n)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NextN = %v\nwant    %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	format := func(times []time.Time) []string {
+		out := make([]string, len(times))
+		for i, v := range times {
+			out[i] = v.Format("01-02 15:04 MST")
+		}
+		return out
+	}
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []string
+	}{
+		// 2026-03-08 02:00 EST jumps to 03:00 EDT: 02:30 doesn't exist that day.
+		{"skipped time", "30 2 * * *", time.Date(2026, 3, 7, 12, 0, 0, 0, ny), []string{
+			"03-09 02:30 EDT", "03-10 02:30 EDT",
+		}},
+		{"hourly over the gap", "0 * * * *", time.Date(2026, 3, 8, 0, 30, 0, 0, ny), []string{
+			"03-08 01:00 EST", "03-08 03:00 EDT", "03-08 04:00 EDT",
+		}},
+		// 2026-11-01 02:00 EDT falls back to 01:00 EST: 01:30 happens twice
+		// and fires once.
+		{"repeated time", "30 1 * * *", time.Date(2026, 11, 1, 0, 0, 0, 0, ny), []string{
+			"11-01 01:30 EDT", "11-02 01:30 EST",
+		}},
+		{"from inside the repeated hour", "40 1 * * *", time.Date(2026, 11, 1, 1, 10, 0, 0, time.FixedZone("EST", -5*3600)).In(ny), []string{
+			"11-02 01:40 EST",
+		}},
+		{"hourly over the repeat", "0 * * * *", time.Date(2026, 11, 1, 0, 30, 0, 0, ny), []string{
+			"11-01 01:00 EDT", "11-01 02:00 EST", "11-01 03:00 EST",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := format(c.NextN(tt.from, len(tt.want))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NextN = %v\nwant    %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@fortnightly",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"* * * foo *",
+		"* * * * monday",
+		"1,,2 * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q): want an error", expr)
+		}
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"30s", 30 * time.Second},
+		{"15m", 15 * time.Minute},
+		{"2h", 2 * time.Hour},
+		{"45", 45 * time.Minute},
+		{" 5m ", 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got, err := ParseInterval(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseInterval(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "m", "0m", "-5m", "1d", "1.5h", "h2"} {
+		if _, err := ParseInterval(in); err == nil {
+			t.Errorf("ParseInterval(%q): want an error", in)
+		}
+	}
+}
+
+// TestCronToOnCalendar checks lib/cron.sh, which turns the same
+// expressions into systemd timers.
+func TestCronToOnCalendar(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+	tests := []struct {
+		expr string
+		want string // "" if it must be rejected
+	}{
+		{"*/15 * * * *", "OnCalendar=*-*-* *:00,15,30,45:00"},
+		{"0 1-5/2 * * *", "OnCalendar=*-*-* 01,03,05:00:00"},
+		{"45 8 * * MON-FRI", "OnCalendar=Mon,Tue,Wed,Thu,Fri *-*-* 08:45:00"},
+		{"0 0 1 JAN,jul *", "OnCalendar=*-1,7-1 00:00:00"},
+		{"0 12 * * 0", "OnCalendar=Sun *-*-* 12:00:00"},
+		{"0 12 * * 7", "OnCalendar=Sun *-*-* 12:00:00"},
+		{"0 12 * * 0-7", "OnCalendar=*-*-* 12:00:00"},
+		{"0 12 * * fri-7", "OnCalendar=Fri,Sat,Sun *-*-* 12:00:00"},
+		{"0 0 13 * fri", "OnCalendar=Fri *-*-* 00:00:00\nOnCalendar=*-*-13 00:00:00"},
+		{"0 0 */10 * mon", "OnCalendar=Mon *-*-1,11,21,31 00:00:00"},
+		{"@weekly", "OnCalendar=Sun *-*-* 00:00:00"},
+		{"@daily", "OnCalendar=*-*-* 00:00:00"},
+		{"@hourly", "OnCalendar=*-*-* *:00:00"},
+		{"@fortnightly", ""},
+		{"60 * * * *", ""},
+		{"* * * * 8", ""},
+		{"*/0 * * * *", ""},
+		{"* * * *", ""},
+		{"* * * * * *", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cmd := exec.Command(bash, "-c", `set -euo pipefail; source ../../../lib/cron.sh; cron_to_oncalendar "$1"`, "_", tt.expr)
+			out, err := cmd.Output()
+			got := strings.TrimSpace(string(out))
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("cron_to_oncalendar accepted it: %q", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("cron_to_oncalendar = %q, %v\nwant %q", got, err, tt.want)
+			}
+		})
+	}
+}
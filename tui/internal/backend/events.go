@@ -0,0 +1,331 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Event types in Claude's stream-json output (`claude -p --output-format
+// stream-json --verbose`), which the runner writes to logs/<id>.log.
+const (
+	EventSystem    = "system"    // session metadata; the "init" subtype opens every log
+	EventAssistant = "assistant" // a model turn: text, thinking and tool calls
+	EventUser      = "user"      // tool results sent back to the model
+	EventResult    = "result"    // the final event, with cost and usage
+	EventToolUse   = "tool_use"  // older CLIs wrote tool calls as top-level events
+)
+
+// Content block types.
+const (
+	BlockText       = "text"
+	BlockThinking   = "thinking"
+	BlockToolUse    = "tool_use"
+	BlockToolResult = "tool_result"
+)
+
+// StreamEvent represents a single line from Claude's stream-json output.
+// The fields of system/init and result events are top-level in the JSON and
+// are embedded here the same way.
+type StreamEvent struct {
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype,omitempty"` // "init"; "success", "error_max_turns", ...
+	UUID      string `json:"uuid,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	// ParentToolUseID is set on the messages of a subagent and is the ID of
+	// the Task tool call that started it.
+	ParentToolUseID string       `json:"parent_tool_use_id,omitempty"`
+	Message         *MessageBody `json:"message,omitempty"` // assistant and user events
+	// ToolUseResult is the tool's structured output on user events. Its
+	// shape depends on the tool.
+	ToolUseResult json.RawMessage `json:"tool_use_result,omitempty"`
+
+	SystemInit
+	ResultInfo
+
+	// tool_use fields (flattened in older stream-json)
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// SystemInit holds the fields of the system/init event.
+type SystemInit struct {
+	Cwd            string      `json:"cwd,omitempty"`
+	Model          string      `json:"model,omitempty"`
+	PermissionMode string      `json:"permissionMode,omitempty"`
+	Tools          []string    `json:"tools,omitempty"`
+	MCPServers     []MCPServer `json:"mcp_servers,omitempty"`
+	SlashCommands  []string    `json:"slash_commands,omitempty"`
+	APIKeySource   string      `json:"apiKeySource,omitempty"`
+	Version        string      `json:"claude_code_version,omitempty"`
+}
+
+// MCPServer is an MCP server listed in the init event.
+type MCPServer struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// ResultInfo holds the fields of the result event.
+type ResultInfo struct {
+	Result            string             `json:"result,omitempty"`
+	IsError           bool               `json:"is_error,omitempty"`
+	DurationMs        int                `json:"duration_ms,omitempty"`
+	DurationAPIMs     int                `json:"duration_api_ms,omitempty"`
+	NumTurns          int                `json:"num_turns,omitempty"`
+	TotalCostUSD      float64            `json:"total_cost_usd,omitempty"`
+	Usage             *Usage             `json:"usage,omitempty"`
+	PermissionDenials []PermissionDenial `json:"permission_denials,omitempty"`
+}
+
+// PermissionDenial is a tool call the permission mode refused.
+type PermissionDenial struct {
+	ToolName  string          `json:"tool_name"`
+	ToolUseID string          `json:"tool_use_id"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+}
+
+// Usage is the token usage block of a result event or assistant message.
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+}
+
+// IsInit reports whether e is the system/init event.
+func (e StreamEvent) IsInit() bool {
+	return e.Type == EventSystem && e.Subtype == "init"
+}
+
+// IsSubagent reports whether e belongs to a subagent rather than the main
+// conversation.
+func (e StreamEvent) IsSubagent() bool {
+	return e.ParentToolUseID != ""
+}
+
+// Blocks returns the content blocks of an assistant or user event.
+func (e StreamEvent) Blocks() Content {
+	if e.Message == nil {
+		return nil
+	}
+	return e.Message.Content
+}
+
+// MessageBody is the message field of an assistant or user StreamEvent.
+type MessageBody struct {
+	ID         string  `json:"id,omitempty"`
+	Role       string  `json:"role,omitempty"`
+	Model      string  `json:"model,omitempty"`
+	Content    Content `json:"content"`
+	StopReason string  `json:"stop_reason,omitempty"`
+	Usage      *Usage  `json:"usage,omitempty"`
+}
+
+// ContentBlock is one block of a message: a TextBlock, ThinkingBlock,
+// ToolUseBlock or ToolResultBlock, or an OtherBlock for any other type.
+type ContentBlock interface {
+	BlockType() string
+}
+
+// TextBlock is plain text from the model, or from a tool result.
+type TextBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ThinkingBlock is the model's extended thinking.
+type ThinkingBlock struct {
+	Type      string `json:"type"`
+	Thinking  string `json:"thinking"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// ToolUseBlock is a tool call. Input is kept raw, since its shape depends
+// on the tool; Args decodes the fields of the built-in tools.
+type ToolUseBlock struct {
+	Type  string          `json:"type"`
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// ToolResultBlock is the output of a tool call, sent back in a user event.
+type ToolResultBlock struct {
+	Type      string  `json:"type"`
+	ToolUseID string  `json:"tool_use_id"`
+	Content   Content `json:"content,omitempty"`
+	IsError   bool    `json:"is_error,omitempty"`
+}
+
+// OtherBlock is a block of a type not modeled above (images, redacted
+// thinking, server tools, ...). Raw is the block as it appeared in the log.
+type OtherBlock struct {
+	Type string
+	Raw  json.RawMessage
+}
+
+func (b TextBlock) BlockType() string       { return BlockText }
+func (b ThinkingBlock) BlockType() string   { return BlockThinking }
+func (b ToolUseBlock) BlockType() string    { return BlockToolUse }
+func (b ToolResultBlock) BlockType() string { return BlockToolResult }
+func (b OtherBlock) BlockType() string      { return b.Type }
+
+// MarshalJSON writes the block back as it was read.
+func (b OtherBlock) MarshalJSON() ([]byte, error) {
+	if len(b.Raw) == 0 {
+		return json.Marshal(map[string]string{"type": b.Type})
+	}
+	return b.Raw, nil
+}
+
+var errUnknownBlock = errors.New("unknown content block")
+
+// Content is the content of a message. In the JSON it is either an array of
+// blocks or, for plain user messages and simple tool results, a string,
+// which reads as a single TextBlock.
+type Content []ContentBlock
+
+func (c *Content) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		*c = nil
+		return nil
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*c = Content{TextBlock{Type: BlockText, Text: s}}
+		return nil
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return fmt.Errorf("content: %w", err)
+	}
+	blocks := make(Content, 0, len(raws))
+	for _, raw := range raws {
+		blocks = append(blocks, unmarshalBlock(raw))
+	}
+	*c = blocks
+	return nil
+}
+
+// unmarshalBlock decodes a block by its type. A block that doesn't decode
+// as its type is kept as an OtherBlock rather than failing the whole event.
+func unmarshalBlock(raw json.RawMessage) ContentBlock {
+	var head struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal(raw, &head)
+
+	var (
+		b   ContentBlock
+		err error
+	)
+	switch head.Type {
+	case BlockText:
+		var t TextBlock
+		err = json.Unmarshal(raw, &t)
+		b = t
+	case BlockThinking:
+		var t ThinkingBlock
+		err = json.Unmarshal(raw, &t)
+		b = t
+	case BlockToolUse:
+		var t ToolUseBlock
+		err = json.Unmarshal(raw, &t)
+		b = t
+	case BlockToolResult:
+		var t ToolResultBlock
+		err = json.Unmarshal(raw, &t)
+		b = t
+	default:
+		err = errUnknownBlock
+	}
+	if err != nil {
+		return OtherBlock{Type: head.Type, Raw: append(json.RawMessage(nil), raw...)}
+	}
+	return b
+}
+
+// Text joins the text blocks of the content, one per line.
+func (c Content) Text() string {
+	var parts []string
+	for _, b := range c {
+		if t, ok := b.(TextBlock); ok && t.Text != "" {
+			parts = append(parts, t.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ToolInput holds the input fields of the built-in tools. A field the tool
+// doesn't take is left empty.
+type ToolInput struct {
+	Command      string     `json:"command,omitempty"`       // Bash
+	Description  string     `json:"description,omitempty"`   // Bash, Task
+	FilePath     string     `json:"file_path,omitempty"`     // Read, Write, Edit, MultiEdit
+	NotebookPath string     `json:"notebook_path,omitempty"` // NotebookEdit
+	Content      string     `json:"content,omitempty"`       // Write
+	OldString    string     `json:"old_string,omitempty"`    // Edit
+	NewString    string     `json:"new_string,omitempty"`    // Edit
+	ReplaceAll   bool       `json:"replace_all,omitempty"`   // Edit
+	Edits        []EditSpan `json:"edits,omitempty"`         // MultiEdit
+	Pattern      string     `json:"pattern,omitempty"`       // Glob, Grep
+	Path         string     `json:"path,omitempty"`          // Glob, Grep, LS
+	URL          string     `json:"url,omitempty"`           // WebFetch
+	Query        string     `json:"query,omitempty"`         // WebSearch
+	Prompt       string     `json:"prompt,omitempty"`        // Task, WebFetch
+	SubagentType string     `json:"subagent_type,omitempty"` // Task
+	NewSource    string     `json:"new_source,omitempty"`    // NotebookEdit
+	CellID       string     `json:"cell_id,omitempty"`       // NotebookEdit
+	EditMode     string     `json:"edit_mode,omitempty"`     // NotebookEdit: replace, insert, delete
+}
+
+// EditSpan is one replacement of a MultiEdit call.
+type EditSpan struct {
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all,omitempty"`
+}
+
+// Args decodes the input of a built-in tool. Fields that don't match the
+// expected types are skipped, so tools with other inputs decode to what
+// they share with the built-in ones.
+func (b ToolUseBlock) Args() ToolInput {
+	return decodeToolInput(b.Input)
+}
+
+// Summary returns the argument that best describes the call on one line:
+// the command, file, pattern, URL or query.
+func (b ToolUseBlock) Summary() string {
+	in := b.Args()
+	for _, s := range []string{in.Command, in.FilePath, in.NotebookPath, in.Pattern, in.URL, in.Query, in.Description} {
+		if s = strings.TrimSpace(s); s != "" {
+			if i := strings.IndexByte(s, '\n'); i >= 0 {
+				s = s[:i] + " …"
+			}
+			return s
+		}
+	}
+	return ""
+}
+
+// Args decodes the input of a denied tool call, as ToolUseBlock.Args does.
+func (d PermissionDenial) Args() ToolInput {
+	return decodeToolInput(d.ToolInput)
+}
+
+func decodeToolInput(raw json.RawMessage) ToolInput {
+	var in ToolInput
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &in)
+	}
+	return in
+}
@@ -0,0 +1,262 @@
+package backend
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestContentUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want Content
+	}{
+		{
+			name: "null",
+			json: `null`,
+			want: nil,
+		},
+		{
+			name: "string",
+			json: `"plain prompt text"`,
+			want: Content{TextBlock{Type: BlockText, Text: "plain prompt text"}},
+		},
+		{
+			name: "empty array",
+			json: `[]`,
+			want: Content{},
+		},
+		{
+			name: "known blocks",
+			json: `[{"type":"thinking","thinking":"hmm","signature":"sig"},{"type":"text","text":"hi"},{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"/a"}}]`,
+			want: Content{
+				ThinkingBlock{Type: BlockThinking, Thinking: "hmm", Signature: "sig"},
+				TextBlock{Type: BlockText, Text: "hi"},
+				ToolUseBlock{Type: BlockToolUse, ID: "t1", Name: "Read", Input: json.RawMessage(`{"file_path":"/a"}`)},
+			},
+		},
+		{
+			name: "tool result with string content",
+			json: `[{"type":"tool_result","tool_use_id":"t1","content":"ok","is_error":true}]`,
+			want: Content{
+				ToolResultBlock{Type: BlockToolResult, ToolUseID: "t1", Content: Content{TextBlock{Type: BlockText, Text: "ok"}}, IsError: true},
+			},
+		},
+		{
+			name: "tool result with block content",
+			json: `[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"a"},{"type":"text","text":"b"}]}]`,
+			want: Content{
+				ToolResultBlock{Type: BlockToolResult, ToolUseID: "t1", Content: Content{
+					TextBlock{Type: BlockText, Text: "a"},
+					TextBlock{Type: BlockText, Text: "b"},
+				}},
+			},
+		},
+		{
+			name: "unknown block",
+			json: `[{"type":"image","source":{"type":"base64","data":"iVBO"}}]`,
+			want: Content{
+				OtherBlock{Type: "image", Raw: json.RawMessage(`{"type":"image","source":{"type":"base64","data":"iVBO"}}`)},
+			},
+		},
+		{
+			name: "malformed known block",
+			json: `[{"type":"text","text":42},{"type":"text","text":"kept"}]`,
+			want: Content{
+				OtherBlock{Type: BlockText, Raw: json.RawMessage(`{"type":"text","text":42}`)},
+				TextBlock{Type: BlockText, Text: "kept"},
+			},
+		},
+		{
+			name: "block without a type",
+			json: `[{"text":"no type"}]`,
+			want: Content{
+				OtherBlock{Raw: json.RawMessage(`{"text":"no type"}`)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Content
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentUnmarshalErrors(t *testing.T) {
+	for _, in := range []string{`{"type":"text"}`, `42`, `"unterminated`} {
+		var c Content
+		if err := c.UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("UnmarshalJSON(%s): want an error, got %#v", in, c)
+		}
+	}
+}
+
+func TestOtherBlockRoundTrip(t *testing.T) {
+	in := `{"role":"user","content":[{"type":"redacted_thinking","data":"EmwK"},{"type":"server_tool_use","id":"s1","name":"web_search","input":{"query":"go"}}]}`
+	var m MessageBody
+	if err := json.Unmarshal([]byte(in), &m); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range m.Content {
+		if _, ok := b.(OtherBlock); !ok {
+			t.Fatalf("block %s decoded as %T, want OtherBlock", b.BlockType(), b)
+		}
+	}
+	out, err := json.Marshal(m.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"type":"redacted_thinking","data":"EmwK"},{"type":"server_tool_use","id":"s1","name":"web_search","input":{"query":"go"}}]`; string(out) != want {
+		t.Errorf("Marshal = %s\nwant      %s", out, want)
+	}
+
+	out, err = json.Marshal(OtherBlock{Type: "image"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"type":"image"}` {
+		t.Errorf("Marshal without Raw = %s", out)
+	}
+}
+
+func readTestLog(t *testing.T, name string) []StreamEvent {
+	t.Helper()
+	events, err := ParseLogFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestParseRecordedSession(t *testing.T) {
+	events := readTestLog(t, "session.jsonl")
+	if len(events) != 12 {
+		t.Fatalf("got %d events, want 12", len(events))
+	}
+
+	first := events[0]
+	if !first.IsInit() || first.Model != "claude-sonnet-4-5" || first.Cwd != "/home/me/notes" || len(first.MCPServers) != 1 {
+		t.Errorf("init event = %+v", first.SystemInit)
+	}
+
+	var subagent []string
+	for _, e := range events {
+		if e.IsSubagent() {
+			subagent = append(subagent, e.UUID)
+			if e.ParentToolUseID != "toolu_02" {
+				t.Errorf("event %s: parent_tool_use_id = %q", e.UUID, e.ParentToolUseID)
+			}
+		}
+	}
+	if want := []string{"2b", "2c", "2d"}; !reflect.DeepEqual(subagent, want) {
+		t.Errorf("subagent events = %v, want %v (a null parent_tool_use_id is the main conversation)", subagent, want)
+	}
+
+	if got := events[1].Blocks(); len(got) != 3 || got[0].BlockType() != BlockThinking || got[2].BlockType() != BlockToolUse {
+		t.Errorf("first assistant turn = %#v", got)
+	}
+	if got := events[2].Blocks()[0].(ToolResultBlock).Content.Text(); got != "/home/me/notes/inbox/a.md\n/home/me/notes/inbox/b.md" {
+		t.Errorf("string tool result = %q", got)
+	}
+	if len(events[2].ToolUseResult) == 0 {
+		t.Error("tool_use_result was dropped")
+	}
+
+	result := events[len(events)-1]
+	if result.Type != EventResult || result.NumTurns != 6 || result.TotalCostUSD != 0.0841 || result.Usage == nil || result.Usage.CacheReadInputTokens != 36000 {
+		t.Errorf("result event = %+v", result.ResultInfo)
+	}
+	if len(result.PermissionDenials) != 1 {
+		t.Fatalf("permission_denials = %+v", result.PermissionDenials)
+	}
+	d := result.PermissionDenials[0]
+	if d.ToolName != "Bash" || d.ToolUseID != "toolu_04" || d.Args().Command != "rm -rf inbox/processed" {
+		t.Errorf("denial = %+v", d)
+	}
+	if denials := ExtractDenials(events); len(denials) != 1 || denials[0].Rule() != d.Rule() {
+		t.Errorf("ExtractDenials = %+v", denials)
+	}
+
+	tr := NewTranscript(events)
+	var calls, answered, sub int
+	for _, e := range tr.Entries {
+		if !e.IsTool() {
+			continue
+		}
+		calls++
+		if e.Result != nil {
+			answered++
+		}
+		if e.Subagent {
+			sub++
+		}
+	}
+	if calls != 4 || answered != 4 || sub != 1 {
+		t.Errorf("transcript: %d calls, %d answered, %d from the subagent; want 4, 4, 1", calls, answered, sub)
+	}
+}
+
+func TestParseLegacyToolUse(t *testing.T) {
+	events := readTestLog(t, "legacy.jsonl")
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5", len(events))
+	}
+	e := events[2]
+	if e.Type != EventToolUse || e.ID != "toolu_legacy" || e.Name != "Bash" || string(e.Input) != `{"command":"make build"}` {
+		t.Errorf("top-level tool_use = %+v", e)
+	}
+
+	tr := NewTranscript(events)
+	var call *TranscriptEntry
+	for i := range tr.Entries {
+		if tr.Entries[i].IsTool() {
+			call = &tr.Entries[i]
+		}
+	}
+	if call == nil || call.Tool.Summary() != "make build" || call.Result == nil || call.Result.Content.Text() != "ok" {
+		t.Errorf("legacy tool call in transcript = %+v", call)
+	}
+	if last := tr.Entries[len(tr.Entries)-1]; last.Text != "The build passes." {
+		t.Errorf("last entry = %+v, want the result", last)
+	}
+}
+
+func TestParseOddBlocks(t *testing.T) {
+	events := readTestLog(t, "odd_blocks.jsonl")
+	// The line that isn't JSON is skipped; the rest all parse.
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5", len(events))
+	}
+
+	var types []string
+	for _, b := range events[1].Blocks() {
+		types = append(types, b.BlockType())
+	}
+	if want := []string{"redacted_thinking", "server_tool_use", BlockText, BlockText}; !reflect.DeepEqual(types, want) {
+		t.Errorf("block types = %v, want %v", types, want)
+	}
+	if _, ok := events[1].Blocks()[2].(OtherBlock); !ok {
+		t.Errorf("malformed text block decoded as %T, want OtherBlock", events[1].Blocks()[2])
+	}
+	if got := events[1].Blocks().Text(); got != "Found it." {
+		t.Errorf("Text() = %q", got)
+	}
+
+	if b, ok := events[2].Blocks()[0].(OtherBlock); !ok || b.Type != "image" {
+		t.Errorf("image block = %#v", events[2].Blocks()[0])
+	}
+	if got := events[3].Blocks().Text(); got != "plain prompt text" {
+		t.Errorf("string user content = %q", got)
+	}
+	if r := events[4]; r.Subtype != "error_max_turns" || !r.IsError {
+		t.Errorf("result = %+v", r)
+	}
+}
@@ -0,0 +1,331 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Export formats.
+const (
+	ExportMarkdown = "md"
+	ExportHTML     = "html"
+	ExportJSON     = "json"
+)
+
+// maxExportResultLines caps the tool results in Markdown and HTML exports,
+// which are meant to be read. JSON exports keep results whole.
+const maxExportResultLines = 40
+
+// ParseExportFormat accepts a format name or file extension.
+func ParseExportFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, ".")) {
+	case "md", "markdown":
+		return ExportMarkdown, nil
+	case "html", "htm":
+		return ExportHTML, nil
+	case "json":
+		return ExportJSON, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (expected md, html or json)", s)
+}
+
+// ExportFormatFor guesses the format from an output path's extension,
+// defaulting to Markdown.
+func ExportFormatFor(path string) string {
+	if f, err := ParseExportFormat(filepath.Ext(path)); err == nil {
+		return f
+	}
+	return ExportMarkdown
+}
+
+// ExportSession writes a session's transcript to path ("-" for stdout).
+func (c *Client) ExportSession(sess Session, format, path string) error {
+	events, err := c.ReadLog(sess.ID)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := Export(&buf, sess, events, format); err != nil {
+		return err
+	}
+	if path == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	return nil
+}
+
+// Export renders a session and its log events in the given format.
+func Export(w io.Writer, sess Session, events []StreamEvent, format string) error {
+	t := NewTranscript(events)
+	switch format {
+	case ExportMarkdown:
+		return exportMarkdown(w, sess, t)
+	case ExportHTML:
+		return exportHTML(w, sess, t)
+	case ExportJSON:
+		return exportJSON(w, sess, events, t)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// exportTitle is the heading of an export.
+func exportTitle(sess Session) string {
+	name := sess.Label
+	if name == "" {
+		name = sess.Trigger
+	}
+	return fmt.Sprintf("%s (%s)", name, sess.Short)
+}
+
+// exportFields lists the session metadata shown at the top of an export.
+func exportFields(sess Session) [][2]string {
+	fields := [][2]string{
+		{"Session", sess.ID},
+		{"Trigger", sess.Trigger},
+		{"Status", sess.Status},
+		{"Started", sess.Started},
+	}
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+	if sess.Duration > 0 {
+		add("Duration", (time.Duration(sess.Duration) * time.Second).String())
+	}
+	add("Directory", sess.WorkingDir)
+	add("Claude session", sess.SessionID)
+	if files := sess.InputFiles(); len(files) > 0 {
+		add("Files", strings.Join(files, ", "))
+	}
+	if sess.HasStats() {
+		add("Cost", fmt.Sprintf("$%.2f", sess.CostUSD))
+		add("Tokens", fmt.Sprintf("%d in, %d out", sess.PromptTokens(), sess.OutputTokens))
+		add("Turns", fmt.Sprintf("%d", sess.NumTurns))
+	}
+	add("Error", sess.Error)
+	return fields
+}
+
+// toolInputText returns a tool call's input as text for a code block, and
+// the block's language: the command for Bash, a diff for edits, the content
+// for writes, and indented JSON otherwise.
+func toolInputText(t ToolUseBlock) (text, lang string) {
+	in := t.Args()
+	switch t.Name {
+	case "Bash":
+		return in.Command, "sh"
+	case "Edit":
+		return in.FilePath + "\n" + editDiff(in.OldString, in.NewString), "diff"
+	case "MultiEdit":
+		parts := []string{in.FilePath}
+		for _, e := range in.Edits {
+			parts = append(parts, editDiff(e.OldString, e.NewString))
+		}
+		return strings.Join(parts, "\n"), "diff"
+	case "Write":
+		return in.FilePath + "\n" + in.Content, ""
+	}
+	var b bytes.Buffer
+	if len(t.Input) == 0 || json.Indent(&b, t.Input, "", "  ") != nil {
+		return string(t.Input), "json"
+	}
+	return b.String(), "json"
+}
+
+// editDiff renders a replacement as removed and added lines.
+func editDiff(before, after string) string {
+	var b strings.Builder
+	for _, l := range strings.Split(strings.TrimRight(before, "\n"), "\n") {
+		b.WriteString("- " + l + "\n")
+	}
+	for _, l := range strings.Split(strings.TrimRight(after, "\n"), "\n") {
+		b.WriteString("+ " + l + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// resultText returns a tool result cut to maxExportResultLines.
+func resultText(r *ToolResultBlock) string {
+	text := strings.TrimRight(r.Content.Text(), "\n")
+	lines := strings.Split(text, "\n")
+	if len(lines) > maxExportResultLines {
+		more := len(lines) - maxExportResultLines
+		text = strings.Join(lines[:maxExportResultLines], "\n") + fmt.Sprintf("\n… %d more lines", more)
+	}
+	return text
+}
+
+// fence wraps text in a Markdown code block, with a fence longer than any
+// backtick run inside it.
+func fence(text, lang string) string {
+	ticks, run := 3, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			ticks = max(ticks, run+1)
+		} else {
+			run = 0
+		}
+	}
+	f := strings.Repeat("`", ticks)
+	return f + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + f + "\n"
+}
+
+func exportMarkdown(w io.Writer, sess Session, t *Transcript) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", exportTitle(sess))
+	b.WriteString("| | |\n|---|---|\n")
+	for _, f := range exportFields(sess) {
+		fmt.Fprintf(&b, "| %s | %s |\n", f[0], strings.ReplaceAll(f[1], "|", `\|`))
+	}
+	b.WriteString("\n## Transcript\n\n")
+
+	for _, e := range t.Entries {
+		quote := ""
+		if e.Subagent {
+			quote = "> "
+		}
+		if !e.IsTool() {
+			for _, l := range strings.Split(strings.TrimRight(e.Text, "\n"), "\n") {
+				b.WriteString(strings.TrimRight(quote+l, " ") + "\n")
+			}
+			b.WriteString("\n")
+			continue
+		}
+
+		status := ""
+		if e.Result != nil && e.Result.IsError {
+			status = " (failed)"
+		}
+		fmt.Fprintf(&b, "%s**Tool: %s**%s\n\n", quote, e.Tool.Name, status)
+		text, lang := toolInputText(*e.Tool)
+		b.WriteString(fence(text, lang))
+		if e.Result != nil {
+			if r := resultText(e.Result); r != "" {
+				b.WriteString("\n" + fence(r, "text"))
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type htmlEntry struct {
+	TranscriptEntry
+	Input, Lang, Result string
+}
+
+var exportTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font: 15px/1.5 system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; color: #1f2328; }
+table { border-collapse: collapse; margin-bottom: 2rem; }
+th, td { text-align: left; padding: .2rem .8rem .2rem 0; vertical-align: top; }
+th { color: #59636e; font-weight: normal; }
+pre { background: #f6f8fa; padding: .6rem; overflow-x: auto; white-space: pre-wrap; word-break: break-word; }
+.text { white-space: pre-wrap; margin: 1rem 0; }
+.subagent { margin-left: 1.5rem; border-left: 3px solid #d1d9e0; padding-left: .8rem; }
+details { margin: .5rem 0; }
+summary { cursor: pointer; }
+summary code { color: #59636e; }
+.error pre.result { background: #ffebe9; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+{{range .Fields}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+<h2>Transcript</h2>
+{{range .Entries}}{{if .IsTool}}<details class="tool{{if .Subagent}} subagent{{end}}{{if and .TranscriptEntry.Result .TranscriptEntry.Result.IsError}} error{{end}}">
+<summary><strong>{{.Tool.Name}}</strong> <code>{{.Tool.Summary}}</code>{{if and .TranscriptEntry.Result .TranscriptEntry.Result.IsError}} (failed){{end}}</summary>
+<pre class="input lang-{{.Lang}}">{{.Input}}</pre>
+{{if .Result}}<pre class="result">{{.Result}}</pre>
+{{end}}</details>
+{{else}}<div class="text{{if .Subagent}} subagent{{end}}">{{.Text}}</div>
+{{end}}{{end}}</body>
+</html>
+`))
+
+func exportHTML(w io.Writer, sess Session, t *Transcript) error {
+	entries := make([]htmlEntry, 0, len(t.Entries))
+	for _, e := range t.Entries {
+		h := htmlEntry{TranscriptEntry: e}
+		if e.IsTool() {
+			h.Input, h.Lang = toolInputText(*e.Tool)
+			if e.Result != nil {
+				h.Result = resultText(e.Result)
+			}
+		}
+		entries = append(entries, h)
+	}
+	return exportTemplate.Execute(w, struct {
+		Title   string
+		Fields  [][2]string
+		Entries []htmlEntry
+	}{exportTitle(sess), exportFields(sess), entries})
+}
+
+// exportEntry is a transcript entry in a JSON export.
+type exportEntry struct {
+	Type     string          `json:"type"` // "text" or "tool_use"
+	Text     string          `json:"text,omitempty"`
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Input    json.RawMessage `json:"input,omitempty"`
+	Result   *exportResult   `json:"result,omitempty"`
+	Subagent bool            `json:"subagent,omitempty"`
+}
+
+type exportResult struct {
+	Text    string `json:"text"`
+	IsError bool   `json:"is_error,omitempty"`
+}
+
+func exportJSON(w io.Writer, sess Session, events []StreamEvent, t *Transcript) error {
+	out := struct {
+		Session    Session       `json:"session"`
+		Init       *SystemInit   `json:"init,omitempty"`
+		Result     *ResultInfo   `json:"result,omitempty"`
+		Transcript []exportEntry `json:"transcript"`
+	}{Session: sess, Transcript: []exportEntry{}}
+
+	for _, e := range events {
+		switch {
+		case e.IsInit():
+			out.Init = &e.SystemInit
+		case e.Type == EventResult:
+			out.Result = &e.ResultInfo
+		}
+	}
+	for _, e := range t.Entries {
+		x := exportEntry{Type: BlockText, Text: e.Text, Subagent: e.Subagent}
+		if e.IsTool() {
+			x = exportEntry{Type: BlockToolUse, ID: e.Tool.ID, Name: e.Tool.Name, Input: e.Tool.Input, Subagent: e.Subagent}
+			if r := e.Result; r != nil {
+				x.Result = &exportResult{Text: r.Content.Text(), IsError: r.IsError}
+			}
+		}
+		out.Transcript = append(out.Transcript, x)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
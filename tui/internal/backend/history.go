@@ -0,0 +1,201 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// SessionDiff is the change to the session list since the previous read.
+type SessionDiff struct {
+	// Reset means the file was read from the start (first read, truncation,
+	// or replacement by `workmode history compact`). Added then holds every
+	// session and the previous list must be discarded.
+	Reset   bool
+	Added   []Session // sessions not seen before, newest first
+	Updated []Session // final state of sessions already known
+}
+
+// Empty reports whether the diff changes nothing.
+func (d SessionDiff) Empty() bool {
+	return !d.Reset && len(d.Added) == 0 && len(d.Updated) == 0
+}
+
+// Apply returns sessions (newest first) with the diff applied. The input
+// slice is not modified, since views may hold on to it.
+func (d SessionDiff) Apply(sessions []Session) []Session {
+	if d.Reset {
+		return d.Added
+	}
+	if len(d.Updated) > 0 {
+		sessions = append([]Session(nil), sessions...)
+		index := make(map[string]int, len(sessions))
+		for i, s := range sessions {
+			index[s.ID] = i
+		}
+		for _, s := range d.Updated {
+			if i, ok := index[s.ID]; ok {
+				sessions[i] = s
+			}
+		}
+	}
+	if len(d.Added) > 0 {
+		sessions = append(append(make([]Session, 0, len(d.Added)+len(sessions)), d.Added...), sessions...)
+	}
+	return sessions
+}
+
+// HistoryReader reads history.jsonl incrementally. It remembers the byte
+// offset it has parsed up to and the file's identity, so a read after an
+// append only parses the new lines. A truncated or replaced file is read
+// again from the start.
+type HistoryReader struct {
+	path string
+
+	mu     sync.Mutex
+	info   os.FileInfo // identity of the file at offset
+	offset int64       // bytes consumed, always at a line boundary
+	// The start of the file's first line. A file deleted and recreated can
+	// get the same inode back, but not the same first session.
+	head    []byte
+	known   map[string]struct{} // session IDs seen so far
+	started bool                // a first read has happened
+}
+
+// NewHistoryReader creates a reader for history.jsonl at path.
+func NewHistoryReader(path string) *HistoryReader {
+	return &HistoryReader{path: path}
+}
+
+// Read parses lines appended since the last call. A trailing line without a
+// newline is left for the next read, since the runner may still be writing it.
+func (r *HistoryReader) Read() (SessionDiff, error) {
+	return r.read(false)
+}
+
+// read is Read, parsing a trailing line without a newline too if final is
+// set, for a one-shot read with no next read to pick it up.
+func (r *HistoryReader) read(final bool) (SessionDiff, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.Open(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// A missing file is an empty history; report it once.
+			diff := SessionDiff{Reset: r.offset > 0 || len(r.known) > 0 || !r.started}
+			r.reset(nil)
+			r.started = true
+			return diff, nil
+		}
+		return SessionDiff{}, fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return SessionDiff{}, fmt.Errorf("stat history: %w", err)
+	}
+
+	var diff SessionDiff
+	if !r.started || r.info == nil || !os.SameFile(r.info, info) || info.Size() < r.offset || !r.sameHead(f) {
+		r.reset(info)
+		diff.Reset = true
+	}
+	r.started = true
+	if info.Size() == r.offset {
+		return diff, nil
+	}
+
+	if _, err := f.Seek(r.offset, io.SeekStart); err != nil {
+		return SessionDiff{}, fmt.Errorf("seek history: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return SessionDiff{}, fmt.Errorf("read history: %w", err)
+	}
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if final {
+		end = len(data)
+	}
+	if r.offset == 0 {
+		head := data[:end]
+		if i := bytes.IndexByte(head, '\n'); i >= 0 {
+			head = head[:i+1]
+		}
+		r.head = bytes.Clone(head[:min(len(head), maxHeadLen)])
+	}
+	r.offset += int64(end)
+	r.info = info
+
+	r.parse(data[:end], &diff)
+	return diff, nil
+}
+
+// maxHeadLen caps how much of the first line sameHead compares.
+const maxHeadLen = 512
+
+// sameHead reports whether f still starts with the first line read from it.
+func (r *HistoryReader) sameHead(f *os.File) bool {
+	if len(r.head) == 0 {
+		return true
+	}
+	buf := make([]byte, len(r.head))
+	_, err := f.ReadAt(buf, 0)
+	return err == nil && bytes.Equal(buf, r.head)
+}
+
+func (r *HistoryReader) reset(info os.FileInfo) {
+	r.info = info
+	r.offset = 0
+	r.head = nil
+	r.known = make(map[string]struct{})
+}
+
+// parse folds complete lines into diff. Later lines for the same ID replace
+// earlier ones (last-write-wins); the first line of an ID fixes its position.
+func (r *HistoryReader) parse(data []byte, diff *SessionDiff) {
+	var added, updated []Session
+	addedIdx := make(map[string]int)
+	updatedIdx := make(map[string]int)
+
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var s Session
+		if err := json.Unmarshal(line, &s); err != nil || s.ID == "" {
+			continue // skip malformed lines
+		}
+
+		if i, ok := addedIdx[s.ID]; ok {
+			added[i] = s
+		} else if _, ok := r.known[s.ID]; !ok {
+			r.known[s.ID] = struct{}{}
+			addedIdx[s.ID] = len(added)
+			added = append(added, s)
+		} else if i, ok := updatedIdx[s.ID]; ok {
+			updated[i] = s
+		} else {
+			updatedIdx[s.ID] = len(updated)
+			updated = append(updated, s)
+		}
+	}
+
+	// Reverse so newest sessions are first.
+	for i, j := 0, len(added)-1; i < j; i, j = i+1, j-1 {
+		added[i], added[j] = added[j], added[i]
+	}
+	diff.Added = added
+	diff.Updated = updated
+}
@@ -0,0 +1,226 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	historyRunning   = `{"id":"wm-1","short":"a-1","trigger":"a","status":"running"}` + "\n"
+	historyCompleted = `{"id":"wm-1","short":"a-1","trigger":"a","status":"completed"}` + "\n"
+	historyPartial   = `{"id":"wm-2","short":"b-2","trigger":"b","status":"running"}`
+)
+
+func writeHistory(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSessionsFromFileUnterminatedLine(t *testing.T) {
+	path := writeHistory(t, historyRunning+historyCompleted+historyPartial)
+	sessions, err := ParseSessionsFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+	if sessions[0].ID != "wm-2" || sessions[1].ID != "wm-1" || sessions[1].Status != "completed" {
+		t.Errorf("sessions = %+v", sessions)
+	}
+}
+
+func TestHistoryReaderHoldsPartialLine(t *testing.T) {
+	path := writeHistory(t, historyRunning+historyPartial)
+	r := NewHistoryReader(path)
+
+	diff, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Reset || len(diff.Added) != 1 || diff.Added[0].ID != "wm-1" {
+		t.Fatalf("first read = %+v, want only wm-1", diff)
+	}
+
+	// The runner finishes the line and appends another for wm-1
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("\n" + historyCompleted)
+	f.Close()
+
+	diff, err = r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Reset || len(diff.Added) != 1 || diff.Added[0].ID != "wm-2" {
+		t.Errorf("second read added %+v, want wm-2", diff.Added)
+	}
+	if len(diff.Updated) != 1 || diff.Updated[0].Status != "completed" {
+		t.Errorf("second read updated %+v, want wm-1 completed", diff.Updated)
+	}
+}
+
+func historyLine(id, status string) string {
+	return fmt.Sprintf(`{"id":%q,"short":%q,"trigger":"a","status":%q}`, id, id, status) + "\n"
+}
+
+// sessionStates lists sessions as "id:status", newest first.
+func sessionStates(sessions []Session) string {
+	parts := make([]string, len(sessions))
+	for i, s := range sessions {
+		parts[i] = s.ID + ":" + s.Status
+	}
+	return strings.Join(parts, ",")
+}
+
+func readHistory(t *testing.T, r *HistoryReader) SessionDiff {
+	t.Helper()
+	diff, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return diff
+}
+
+func TestHistoryReaderReset(t *testing.T) {
+	before := historyLine("wm-1", "completed") + historyLine("wm-2", "running")
+	tests := []struct {
+		name   string
+		change func(t *testing.T, path string)
+	}{
+		{"truncated", func(t *testing.T, path string) {
+			if err := os.WriteFile(path, []byte(historyLine("wm-3", "running")), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		// As `workmode history compact` does it, with a file no smaller
+		// than the offset read up to
+		{"replaced by rename", func(t *testing.T, path string) {
+			tmp := path + ".tmp"
+			content := historyLine("wm-2", "completed") + historyLine("wm-3", "running") + historyLine("wm-4", "running")
+			if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(tmp, path); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		// The new file may get the old one's inode back
+		{"deleted and recreated", func(t *testing.T, path string) {
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+			content := historyLine("wm-5", "completed") + historyLine("wm-6", "completed") + historyLine("wm-7", "running")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"deleted", func(t *testing.T, path string) {
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeHistory(t, before)
+			r := NewHistoryReader(path)
+			readHistory(t, r)
+
+			tt.change(t, path)
+			want, err := ParseSessionsFromFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff := readHistory(t, r)
+			if !diff.Reset || len(diff.Updated) != 0 || sessionStates(diff.Added) != sessionStates(want) {
+				t.Errorf("after the change: reset %v, added %s, updated %s; want a reset to %s",
+					diff.Reset, sessionStates(diff.Added), sessionStates(diff.Updated), sessionStates(want))
+			}
+
+			// And it follows the new file from there
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(historyLine("wm-9", "running"))
+			f.Close()
+			got := readHistory(t, r).Apply(diff.Added)
+			if want, _ := ParseSessionsFromFile(path); sessionStates(got) != sessionStates(want) {
+				t.Errorf("append after the change: %s, want %s", sessionStates(got), sessionStates(want))
+			}
+		})
+	}
+}
+
+// TestSessionDiffApply checks that applying each read's diff keeps the same
+// list as parsing the whole file again.
+func TestSessionDiffApply(t *testing.T) {
+	path := writeHistory(t, "")
+	appendHistory := func(s string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(s)
+		f.Close()
+	}
+	rewrite := func(s string) {
+		if err := os.WriteFile(path+".tmp", []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		change func()
+	}{
+		{"empty", func() {}},
+		{"first sessions", func() {
+			appendHistory(historyLine("wm-1", "running") + historyLine("wm-2", "running"))
+		}},
+		{"an update", func() { appendHistory(historyLine("wm-1", "completed")) }},
+		{"added and updated in one read", func() {
+			appendHistory(historyLine("wm-3", "running") + historyLine("wm-2", "error") + historyLine("wm-3", "completed"))
+		}},
+		{"a partial line", func() { appendHistory(`{"id":"wm-4","short":"wm-4","trigger":"a",`) }},
+		{"the line completed", func() { appendHistory(`"status":"running"}` + "\n") }},
+		{"nothing new", func() {}},
+		{"compacted", func() {
+			rewrite(historyLine("wm-3", "completed") + historyLine("wm-4", "running"))
+		}},
+		{"update after compacting", func() { appendHistory(historyLine("wm-4", "completed")) }},
+		{"truncated", func() {
+			if err := os.Truncate(path, 0); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"after truncation", func() { appendHistory(historyLine("wm-5", "running")) }},
+	}
+
+	r := NewHistoryReader(path)
+	var sessions []Session
+	for _, s := range steps {
+		s.change()
+		sessions = readHistory(t, r).Apply(sessions)
+		want, err := ParseSessionsFromFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sessionStates(sessions) != sessionStates(want) {
+			t.Errorf("%s: applied %s, parsed %s", s.name, sessionStates(sessions), sessionStates(want))
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Check formats: how a fan-out trigger's check command prints its items.
+const (
+	CheckJSONLines = "json_lines" // one JSON value per line
+	CheckJSON      = "json"       // a JSON array
+)
+
+// Item is one value a fan-out trigger's check printed, and the key that
+// tells it apart from the items of earlier runs.
+type Item struct {
+	Key   string
+	Value json.RawMessage // compact
+}
+
+// ParseItems splits a check command's output into items.
+func ParseItems(data []byte, format, keyField string) ([]Item, error) {
+	var values []json.RawMessage
+	switch format {
+	case CheckJSON:
+		if len(bytes.TrimSpace(data)) == 0 {
+			return nil, nil
+		}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("check output is not a JSON array: %w", err)
+		}
+	case CheckJSONLines:
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for n := 1; sc.Scan(); n++ {
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			if !json.Valid(line) {
+				return nil, fmt.Errorf("check output line %d is not JSON: %s", n, line)
+			}
+			values = append(values, json.RawMessage(bytes.Clone(line)))
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown check_format %q (expected %s or %s)", format, CheckJSONLines, CheckJSON)
+	}
+
+	items := make([]Item, 0, len(values))
+	for i, v := range values {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, v); err != nil {
+			return nil, err
+		}
+		key, err := itemKey(buf.Bytes(), keyField)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		items = append(items, Item{Key: key, Value: buf.Bytes()})
+	}
+	return items, nil
+}
+
+// itemKey returns an item's key: the keyField of an object item, a string
+// or number item itself, or else a hash of the item.
+func itemKey(value []byte, keyField string) (string, error) {
+	if keyField != "" {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return "", fmt.Errorf("item_key %q needs items that are objects", keyField)
+		}
+		field, ok := obj[keyField]
+		if !ok {
+			return "", fmt.Errorf("item has no %q field", keyField)
+		}
+		value = field
+	}
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		return s, nil
+	}
+	var n json.Number
+	if json.Unmarshal(value, &n) == nil {
+		return n.String(), nil
+	}
+	sum := sha256.Sum256(value)
+	return "sha256:" + hex.EncodeToString(sum[:8]), nil
+}
+
+// ProcessedItems returns the item keys a trigger's item runs have already
+// taken: every run of the item except ones whose last attempt failed,
+// timed out or was skipped, which a later fan-out tries again.
+func ProcessedItems(sessions []Session, trigger string) map[string]bool {
+	last := make(map[string]Session) // retry root → latest attempt
+	for _, s := range sessions {
+		if s.Trigger != trigger || s.ItemKey == "" {
+			continue
+		}
+		if l, ok := last[s.RetryRoot()]; !ok || s.Attempt > l.Attempt {
+			last[s.RetryRoot()] = s
+		}
+	}
+	done := make(map[string]bool)
+	for _, s := range last {
+		switch {
+		case s.RetryAt != "": // failed, another attempt is due
+			done[s.ItemKey] = true
+		case s.Status == "error", s.Status == "timeout", s.Status == "skipped":
+		default:
+			done[s.ItemKey] = true
+		}
+	}
+	return done
+}
+
+// PrunedItems returns the item keys of a trigger's item runs that history
+// prune has dropped since they processed their item. ProcessedItems can't see
+// those runs anymore.
+func (c *Client) PrunedItems(trigger string) (map[string]bool, error) {
+	done := make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(c.stateDir, "processed_items.tsv"))
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		t, raw, ok := strings.Cut(line, "\t")
+		if !ok || t != trigger {
+			continue
+		}
+		// Keys are stored as escaped in history.jsonl
+		var key string
+		if json.Unmarshal([]byte(`"`+raw+`"`), &key) == nil {
+			done[key] = true
+		}
+	}
+	return done, nil
+}
@@ -0,0 +1,135 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// maxTailedLogs is how many log files a LogTailer keeps parsed events for.
+// Moving the selection through the sessions list touches a log per row.
+const maxTailedLogs = 8
+
+// LogRead is the result of reading a log through a LogTailer.
+type LogRead struct {
+	Events []StreamEvent // every event in the log so far
+	Added  int           // how many of Events, at the end, this read parsed
+}
+
+// New returns the events this read added.
+func (r LogRead) New() []StreamEvent {
+	return r.Events[len(r.Events)-r.Added:]
+}
+
+// LogTailer reads stream-json logs incrementally. For each file it keeps
+// the byte offset parsed so far, so reading a growing log only parses the
+// lines appended since the previous read.
+type LogTailer struct {
+	mu    sync.Mutex
+	files map[string]*logTail
+	order []string // least recently read first
+}
+
+type logTail struct {
+	info   os.FileInfo
+	offset int64 // always at a line boundary
+	events []StreamEvent
+}
+
+// NewLogTailer creates an empty tailer.
+func NewLogTailer() *LogTailer {
+	return &LogTailer{files: make(map[string]*logTail)}
+}
+
+// Read parses the lines appended to path since the previous read. A log
+// that was truncated or replaced is read again from the start, as is one
+// evicted from the cache. A missing file reads as empty.
+func (t *LogTailer) Read(path string) (LogRead, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tail := t.touch(path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			*tail = logTail{}
+			return LogRead{}, nil
+		}
+		return LogRead{}, fmt.Errorf("open log: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return LogRead{}, fmt.Errorf("stat log: %w", err)
+	}
+	if tail.info == nil || !os.SameFile(tail.info, info) || info.Size() < tail.offset {
+		*tail = logTail{}
+	}
+	tail.info = info
+	if info.Size() == tail.offset {
+		return LogRead{Events: tail.events}, nil
+	}
+
+	if _, err := f.Seek(tail.offset, io.SeekStart); err != nil {
+		return LogRead{}, fmt.Errorf("seek log: %w", err)
+	}
+	events, n, err := readEvents(bufio.NewReader(f), false)
+	tail.offset += n
+	tail.events = append(tail.events, events...)
+	return LogRead{Events: tail.events, Added: len(events)}, err
+}
+
+// touch returns the cached state for path, marking it most recently used
+// and evicting the oldest entry when the cache is full.
+func (t *LogTailer) touch(path string) *logTail {
+	for i, p := range t.order {
+		if p == path {
+			t.order = append(append(t.order[:i:i], t.order[i+1:]...), path)
+			return t.files[path]
+		}
+	}
+	if len(t.order) >= maxTailedLogs {
+		delete(t.files, t.order[0])
+		t.order = t.order[1:]
+	}
+	tail := &logTail{}
+	t.files[path] = tail
+	t.order = append(t.order, path)
+	return tail
+}
+
+// readEvents parses lines from r, returning the events and the number of
+// bytes consumed. Lines may be any length. A final line without a newline
+// is only parsed if final is set; otherwise it is left unconsumed, since
+// the runner may still be writing it.
+func readEvents(r *bufio.Reader, final bool) ([]StreamEvent, int64, error) {
+	var events []StreamEvent
+	var consumed int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return events, consumed, fmt.Errorf("read log: %w", err)
+		}
+		eof := err != nil
+		if eof && !final {
+			return events, consumed, nil
+		}
+		consumed += int64(len(line))
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var e StreamEvent
+			if json.Unmarshal(line, &e) == nil {
+				events = append(events, e)
+			}
+		}
+		if eof {
+			return events, consumed, nil
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package backend
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func textEvent(text string) string {
+	return fmt.Sprintf(`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":%q}]}}`, text) + "\n"
+}
+
+func appendLog(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTail(t *testing.T, tailer *LogTailer, path string) LogRead {
+	t.Helper()
+	r, err := tailer.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// texts returns the text of each event, for comparing reads.
+func texts(events []StreamEvent) string {
+	var parts []string
+	for _, e := range events {
+		parts = append(parts, e.Blocks().Text())
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestReadEventsLongLine(t *testing.T) {
+	long := strings.Repeat("x", 2<<20) // twice bufio.Scanner's old 1 MiB cap
+	in := textEvent("a") + textEvent(long) + textEvent("b")
+
+	events, n, err := readEvents(bufio.NewReader(strings.NewReader(in)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(in)) || len(events) != 3 {
+		t.Fatalf("consumed %d of %d bytes, %d events; want all and 3", n, len(in), len(events))
+	}
+	if got := events[1].Blocks().Text(); got != long {
+		t.Errorf("long line text has %d bytes, want %d", len(got), len(long))
+	}
+}
+
+func TestReadEventsPartialLine(t *testing.T) {
+	in := textEvent("a") + strings.TrimSuffix(textEvent("b"), "\n")
+
+	events, n, err := readEvents(bufio.NewReader(strings.NewReader(in)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texts(events) != "a" || n != int64(len(textEvent("a"))) {
+		t.Errorf("tailing: events %q, consumed %d; want only the complete line", texts(events), n)
+	}
+
+	events, n, err = readEvents(bufio.NewReader(strings.NewReader(in)), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texts(events) != "a,b" || n != int64(len(in)) {
+		t.Errorf("final read: events %q, consumed %d; want both lines", texts(events), n)
+	}
+}
+
+func TestLogTailerAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wm-1.log")
+	tailer := NewLogTailer()
+
+	if r := readTail(t, tailer, path); len(r.Events) != 0 {
+		t.Fatalf("missing log read %d events", len(r.Events))
+	}
+
+	appendLog(t, path, textEvent("a")+textEvent("b"))
+	if r := readTail(t, tailer, path); texts(r.Events) != "a,b" || r.Added != 2 {
+		t.Fatalf("first read: %q, added %d", texts(r.Events), r.Added)
+	}
+
+	appendLog(t, path, textEvent("c"))
+	r := readTail(t, tailer, path)
+	if texts(r.Events) != "a,b,c" || r.Added != 1 || texts(r.New()) != "c" {
+		t.Fatalf("after append: %q, added %d", texts(r.Events), r.Added)
+	}
+
+	if r := readTail(t, tailer, path); r.Added != 0 || len(r.Events) != 3 {
+		t.Errorf("unchanged log: added %d of %d", r.Added, len(r.Events))
+	}
+}
+
+func TestLogTailerPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wm-1.log")
+	tailer := NewLogTailer()
+
+	line := textEvent("b")
+	appendLog(t, path, textEvent("a")+line[:10])
+	if r := readTail(t, tailer, path); texts(r.Events) != "a" || r.Added != 1 {
+		t.Fatalf("with a partial line: %q, added %d", texts(r.Events), r.Added)
+	}
+
+	appendLog(t, path, line[10:])
+	if r := readTail(t, tailer, path); texts(r.Events) != "a,b" || r.Added != 1 {
+		t.Errorf("line completed: %q, added %d", texts(r.Events), r.Added)
+	}
+}
+
+func TestLogTailerLongLineAcrossReads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wm-1.log")
+	tailer := NewLogTailer()
+
+	long := textEvent(strings.Repeat("y", 3<<20))
+	appendLog(t, path, long[:1<<20])
+	if r := readTail(t, tailer, path); len(r.Events) != 0 {
+		t.Fatalf("read %d events from the start of a line", len(r.Events))
+	}
+	appendLog(t, path, long[1<<20:])
+	r := readTail(t, tailer, path)
+	if r.Added != 1 || len(r.Events[0].Blocks().Text()) != 3<<20 {
+		t.Errorf("long line: added %d", r.Added)
+	}
+}
+
+func TestLogTailerReset(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, path string)
+	}{
+		{"truncated", func(t *testing.T, path string) {
+			if err := os.WriteFile(path, []byte(textEvent("x")), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"replaced", func(t *testing.T, path string) {
+			// A larger file under the same name, as a rename leaves it
+			tmp := path + ".tmp"
+			if err := os.WriteFile(tmp, []byte(textEvent("x")+textEvent("yyyyyyyyyyyyyyyyyyyyyyyyyyyyyy")), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(tmp, path); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"deleted", func(t *testing.T, path string) {
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "wm-1.log")
+			tailer := NewLogTailer()
+			appendLog(t, path, textEvent("a")+textEvent("b"))
+			readTail(t, tailer, path)
+
+			tt.change(t, path)
+			want, err := ParseLogFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := readTail(t, tailer, path)
+			if texts(r.Events) != texts(want) || r.Added != len(want) {
+				t.Errorf("after the change: %q, added %d; want %q, all new", texts(r.Events), r.Added, texts(want))
+			}
+
+			// And it tails the new file from there
+			appendLog(t, path, textEvent("z"))
+			if r := readTail(t, tailer, path); r.Added != 1 || texts(r.New()) != "z" {
+				t.Errorf("append after the change: %q, added %d", texts(r.Events), r.Added)
+			}
+		})
+	}
+}
+
+func TestLogTailerEviction(t *testing.T) {
+	dir := t.TempDir()
+	tailer := NewLogTailer()
+	paths := make([]string, maxTailedLogs+1)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("wm-%d.log", i))
+		appendLog(t, paths[i], textEvent("a"))
+	}
+
+	for _, p := range paths[:maxTailedLogs] {
+		readTail(t, tailer, p)
+	}
+	// Reading paths[0] again makes paths[1] the least recently used
+	readTail(t, tailer, paths[0])
+	readTail(t, tailer, paths[maxTailedLogs])
+
+	if r := readTail(t, tailer, paths[0]); r.Added != 0 {
+		t.Errorf("recently read log was re-parsed (added %d)", r.Added)
+	}
+	if r := readTail(t, tailer, paths[1]); r.Added != 1 || len(r.Events) != 1 {
+		t.Errorf("evicted log: added %d of %d, want it read from the start", r.Added, len(r.Events))
+	}
+}
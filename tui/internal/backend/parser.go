@@ -2,55 +2,20 @@ package backend
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 )
 
 // ParseSessionsFromFile reads history.jsonl and returns deduplicated sessions.
-// Later entries for the same ID overwrite earlier ones (last-write-wins).
+// Later entries for the same ID overwrite earlier ones (last-write-wins). A
+// last line without a trailing newline is parsed too.
 func ParseSessionsFromFile(path string) ([]Session, error) {
-	f, err := os.Open(path)
+	diff, err := NewHistoryReader(path).read(true)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("open history: %w", err)
-	}
-	defer f.Close()
-
-	seen := make(map[string]int) // id → index in result
-	var sessions []Session
-
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		var s Session
-		if err := json.Unmarshal([]byte(line), &s); err != nil {
-			continue // skip malformed lines
-		}
-		if s.ID == "" {
-			continue
-		}
-		if idx, ok := seen[s.ID]; ok {
-			sessions[idx] = s // overwrite with latest status
-		} else {
-			seen[s.ID] = len(sessions)
-			sessions = append(sessions, s)
-		}
-	}
-
-	// Reverse so newest sessions are first.
-	for i, j := 0, len(sessions)-1; i < j; i, j = i+1, j-1 {
-		sessions[i], sessions[j] = sessions[j], sessions[i]
+		return nil, err
 	}
-
-	return sessions, scanner.Err()
+	return diff.Added, nil
 }
 
 // ParseLogFile reads a stream-json log file and returns parsed events.
@@ -64,39 +29,51 @@ func ParseLogFile(path string) ([]StreamEvent, error) {
 	}
 	defer f.Close()
 
-	var events []StreamEvent
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
+	events, _, err := readEvents(bufio.NewReader(f), true)
+	return events, err
+}
+
+// ExtractRunStats returns the stats from the last result event, if any.
+func ExtractRunStats(events []StreamEvent) (RunStats, bool) {
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if e.Type != EventResult {
 			continue
 		}
-		var e StreamEvent
-		if err := json.Unmarshal([]byte(line), &e); err != nil {
-			continue
+		r := RunStats{
+			CostUSD:       e.TotalCostUSD,
+			NumTurns:      e.NumTurns,
+			DurationAPIMs: e.DurationAPIMs,
+			IsError:       e.IsError,
 		}
-		events = append(events, e)
+		if u := e.Usage; u != nil {
+			r.InputTokens = u.InputTokens
+			r.OutputTokens = u.OutputTokens
+			r.CacheReadTokens = u.CacheReadInputTokens
+			r.CacheCreationTokens = u.CacheCreationInputTokens
+		}
+		return r, true
 	}
-	return events, scanner.Err()
+	return RunStats{}, false
 }
 
 // ExtractSummary returns a short text summary from log events.
-// It takes the first non-empty text content from the last assistant message,
-// or the result field, truncated to maxLen.
+// It takes the last text of the main conversation (subagent messages are
+// skipped), or the result field, truncated to maxLen.
 func ExtractSummary(events []StreamEvent, maxLen int) string {
 	var lastText, result string
 	for _, e := range events {
 		switch e.Type {
-		case "assistant":
-			if e.Message != nil {
-				for _, c := range e.Message.Content {
-					if c.Type == "text" && c.Text != "" {
-						lastText = c.Text
-					}
+		case EventAssistant:
+			if e.IsSubagent() {
+				continue
+			}
+			for _, b := range e.Blocks() {
+				if t, ok := b.(TextBlock); ok && t.Text != "" {
+					lastText = t.Text
 				}
 			}
-		case "result":
+		case EventResult:
 			if e.Result != "" {
 				result = e.Result
 			}
@@ -123,25 +100,31 @@ func ExtractSummary(events []StreamEvent, maxLen int) string {
 }
 
 // FormatLogEvents renders stream-json events into human-readable text.
+// Tool calls show their main argument, and failed tool results their
+// first line.
 func FormatLogEvents(events []StreamEvent) string {
 	var b strings.Builder
 	for _, e := range events {
 		switch e.Type {
-		case "assistant":
-			if e.Message != nil {
-				for _, c := range e.Message.Content {
-					switch c.Type {
-					case "text":
+		case EventAssistant, EventUser:
+			for _, c := range e.Blocks() {
+				switch c := c.(type) {
+				case TextBlock:
+					if e.Type == EventAssistant {
 						b.WriteString(c.Text)
 						b.WriteByte('\n')
-					case "tool_use":
-						fmt.Fprintf(&b, "[tool: %s]\n", c.Name)
+					}
+				case ToolUseBlock:
+					b.WriteString(formatToolUse(c.Name, c.Summary()))
+				case ToolResultBlock:
+					if c.IsError {
+						fmt.Fprintf(&b, "[tool error] %s\n", firstLine(c.Content.Text()))
 					}
 				}
 			}
-		case "tool_use":
-			fmt.Fprintf(&b, "[tool: %s]\n", e.Name)
-		case "result":
+		case EventToolUse:
+			b.WriteString(formatToolUse(e.Name, ToolUseBlock{Input: e.Input}.Summary()))
+		case EventResult:
 			if e.Result != "" {
 				b.WriteString(e.Result)
 				b.WriteByte('\n')
@@ -150,3 +133,18 @@ func FormatLogEvents(events []StreamEvent) string {
 	}
 	return b.String()
 }
+
+func formatToolUse(name, summary string) string {
+	if summary == "" {
+		return fmt.Sprintf("[tool: %s]\n", name)
+	}
+	return fmt.Sprintf("[tool: %s] %s\n", name, summary)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
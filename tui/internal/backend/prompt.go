@@ -0,0 +1,259 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// PromptData is what a trigger's prompt template can refer to, as
+// {{.Date}}, {{.CheckOutput}}, {{.Parent.Result}}, {{.Env.HOME}} and so on.
+type PromptData struct {
+	Now         time.Time
+	Date        string // 2006-01-02
+	Time        string // 15:04
+	Trigger     string
+	WorkingDir  string
+	File        string // first input file of a file trigger
+	Files       []string
+	CheckOutput string // what the trigger's check command printed
+	LastResult  string // final message of the trigger's last completed run
+	Item        any    // the item of a fan-out trigger's run, decoded JSON
+	Payload     string // the request body of a webhook trigger's run
+	Parent      PromptParent
+	Env         map[string]string
+}
+
+// PromptParent is the run that chained this one, if any.
+type PromptParent struct {
+	ID        string
+	Short     string
+	Trigger   string
+	Status    string
+	SessionID string // Claude's session ID
+	Result    string
+}
+
+// promptFuncs are the functions prompt templates can call besides the
+// text/template builtins.
+var promptFuncs = template.FuncMap{
+	"env":      os.Getenv,
+	"join":     func(s []string, sep string) string { return strings.Join(s, sep) },
+	"trim":     strings.TrimSpace,
+	"fromJSON": fromJSON,
+}
+
+// fromJSON decodes JSON text, so {{(fromJSON .Payload).ref}} reaches into
+// a webhook's payload.
+func fromJSON(text string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// IsPromptTemplate reports whether a prompt uses {{...}} templating.
+// Prompts without it are sent as they are.
+func IsPromptTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
+
+// ParsePrompt parses a prompt template. Besides syntax errors, it rejects
+// fields PromptData doesn't have, so a typo is caught before a run starts
+// rather than when it renders.
+func ParsePrompt(text string) (*template.Template, error) {
+	t, err := template.New("prompt").Funcs(promptFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFields(t.Root, true); err != nil {
+		return nil, fmt.Errorf("template: prompt: %w", err)
+	}
+	return t, nil
+}
+
+// RenderPrompt renders a prompt template with data.
+func RenderPrompt(text string, data PromptData) (string, error) {
+	t, err := ParsePrompt(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// NewPromptData fills in the variables a prompt gets from the current time,
+// the environment and the trigger's history: LastResult from its last
+// completed run, and Parent from the session (ID or short ID) that chained
+// this run.
+func (c *Client) NewPromptData(trigger, parent string) (PromptData, error) {
+	now := time.Now()
+	d := PromptData{
+		Now:     now,
+		Date:    now.Format("2006-01-02"),
+		Time:    now.Format("15:04"),
+		Trigger: trigger,
+		Env:     make(map[string]string),
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			d.Env[k] = v
+		}
+	}
+
+	sessions, err := c.ReadSessions()
+	if err != nil {
+		return d, err
+	}
+	var last *Session
+	for i := range sessions {
+		s := &sessions[i]
+		if s.Trigger == trigger && s.Status == "completed" && (last == nil || s.Started > last.Started) {
+			last = s
+		}
+		if parent != "" && (s.ID == parent || s.Short == parent) {
+			d.Parent = PromptParent{
+				ID:        s.ID,
+				Short:     s.Short,
+				Trigger:   s.Trigger,
+				Status:    s.Status,
+				SessionID: s.SessionID,
+				Result:    c.resultText(s.ID),
+			}
+		}
+	}
+	if last != nil {
+		d.LastResult = c.resultText(last.ID)
+	}
+	return d, nil
+}
+
+// resultText returns the final message of a session, from its log's result
+// event.
+func (c *Client) resultText(sessionID string) string {
+	events, err := c.ReadLog(sessionID)
+	if err != nil {
+		return ""
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Type == EventResult {
+			return events[i].Result
+		}
+	}
+	return ""
+}
+
+// PromptPath returns the path of the prompt workmode-run sent for a
+// session, saved for auditing.
+func (c *Client) PromptPath(sessionID string) string {
+	return filepath.Join(c.stateDir, "logs", sessionID+".prompt")
+}
+
+// ReadPrompt returns the prompt a session was sent, or "" for runs from
+// before prompts were saved.
+func (c *Client) ReadPrompt(sessionID string) (string, error) {
+	data, err := os.ReadFile(c.PromptPath(sessionID))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+// checkFields walks a template looking for fields PromptData lacks. Fields
+// on dot are only checked where dot is still the data, not inside range
+// or with; $.Field always is.
+func checkFields(node parse.Node, root bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := checkFields(c, root); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkFields(n.Pipe, root)
+	case *parse.TemplateNode:
+		return checkFields(n.Pipe, root)
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode, root, root)
+	case *parse.RangeNode:
+		return checkBranch(&n.BranchNode, false, root)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode, false, root)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkFields(arg, root); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.ChainNode:
+		return checkFields(n.Node, root)
+	case *parse.FieldNode:
+		if root {
+			return checkField(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return checkField(n.Ident[1:])
+		}
+	}
+	return nil
+}
+
+// checkBranch checks an if, range or with: its pipeline against the
+// enclosing dot, its body with dot possibly rebound.
+func checkBranch(n *parse.BranchNode, bodyRoot, root bool) error {
+	if err := checkFields(n.Pipe, root); err != nil {
+		return err
+	}
+	if err := checkFields(n.List, bodyRoot); err != nil {
+		return err
+	}
+	return checkFields(n.ElseList, root)
+}
+
+// checkField follows a chain of field names (.Parent.Result) through
+// PromptData. Maps and methods end the check.
+func checkField(idents []string) error {
+	t := reflect.TypeOf(PromptData{})
+	for i, id := range idents {
+		if _, ok := t.MethodByName(id); ok || t.Kind() != reflect.Struct {
+			return nil
+		}
+		f, ok := t.FieldByName(id)
+		if !ok {
+			return fmt.Errorf("unknown variable .%s (have %s)", strings.Join(idents[:i+1], "."), fieldNames(t))
+		}
+		t = f.Type
+	}
+	return nil
+}
+
+// fieldNames lists a struct's fields as ".A, .B".
+func fieldNames(t reflect.Type) string {
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = "." + t.Field(i).Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,81 @@
+package backend
+
+// Transcript is a log read as a conversation: the model's text and its tool
+// calls, each paired with the result that came back for it.
+type Transcript struct {
+	Entries []TranscriptEntry
+
+	calls map[string]int // tool_use ID → index in Entries
+}
+
+// TranscriptEntry is a piece of text or a tool call.
+type TranscriptEntry struct {
+	Text     string           // assistant text, or the final result
+	Tool     *ToolUseBlock    // set for tool calls
+	Result   *ToolResultBlock // the tool call's result, once it arrived
+	Subagent bool             // from a subagent rather than the main conversation
+}
+
+// IsTool reports whether the entry is a tool call.
+func (e TranscriptEntry) IsTool() bool {
+	return e.Tool != nil
+}
+
+// Add appends events to the transcript. It returns the indexes of earlier
+// entries that changed, which happens when a tool result arrives for a
+// call already in the transcript.
+func (t *Transcript) Add(events []StreamEvent) (changed []int) {
+	if t.calls == nil {
+		t.calls = make(map[string]int)
+	}
+	start := len(t.Entries)
+	for _, e := range events {
+		switch e.Type {
+		case EventAssistant:
+			for _, b := range e.Blocks() {
+				switch b := b.(type) {
+				case TextBlock:
+					if b.Text != "" {
+						t.Entries = append(t.Entries, TranscriptEntry{Text: b.Text, Subagent: e.IsSubagent()})
+					}
+				case ToolUseBlock:
+					t.addCall(b, e.IsSubagent())
+				}
+			}
+		case EventUser:
+			for _, b := range e.Blocks() {
+				r, ok := b.(ToolResultBlock)
+				if !ok {
+					continue
+				}
+				if i, ok := t.calls[r.ToolUseID]; ok {
+					t.Entries[i].Result = &r
+					if i < start {
+						changed = append(changed, i)
+					}
+				}
+			}
+		case EventToolUse:
+			t.addCall(ToolUseBlock{Type: BlockToolUse, ID: e.ID, Name: e.Name, Input: e.Input}, false)
+		case EventResult:
+			if e.Result != "" {
+				t.Entries = append(t.Entries, TranscriptEntry{Text: e.Result})
+			}
+		}
+	}
+	return changed
+}
+
+func (t *Transcript) addCall(b ToolUseBlock, subagent bool) {
+	if b.ID != "" {
+		t.calls[b.ID] = len(t.Entries)
+	}
+	t.Entries = append(t.Entries, TranscriptEntry{Tool: &b, Subagent: subagent})
+}
+
+// NewTranscript builds the transcript of a whole log.
+func NewTranscript(events []StreamEvent) *Transcript {
+	t := &Transcript{}
+	t.Add(events)
+	return t
+}
@@ -1,36 +1,190 @@
 package backend
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Status represents the output of `workmode status --json`.
 type Status struct {
 	Active  bool `json:"active"`
 	Watcher bool `json:"watcher"`
-	Timers  int  `json:"timers"`
+	// Webhooks is true when the workmode-webhook service is serving
+	// webhook triggers.
+	Webhooks bool `json:"webhooks"`
+	// Daemon is true when `workmode daemon` is running.
+	Daemon bool `json:"daemon"`
+	Timers int  `json:"timers"`
 	// Triggers is the total number of configured triggers.
 	Triggers int `json:"triggers"`
 	// Running is the count of currently running sessions (derived from session data).
 	Running int `json:"-"`
 	// Today is the count of sessions started today (derived from session data).
 	Today int `json:"-"`
+	// Queued is the count of runs waiting for a max_parallel slot (derived from session data).
+	Queued int `json:"-"`
+	// Approvals is the count of sessions waiting for tool calls to be approved.
+	Approvals int `json:"-"`
+	// CostToday and TokensToday total the usage of sessions started today.
+	CostToday   float64 `json:"-"`
+	TokensToday int     `json:"-"`
 }
 
 // Session represents a session entry from history.jsonl or `workmode session list --json`.
 type Session struct {
-	ID         string `json:"id"`
-	Short      string `json:"short"`
-	Trigger    string `json:"trigger"`
-	Label      string `json:"label"`
-	WorkingDir string `json:"working_dir"`
-	Started    string `json:"started"`
-	Status     string `json:"status"`
-	Duration   int    `json:"duration,omitempty"`
-	PID        int    `json:"pid,omitempty"`
-	SessionID  string `json:"session_id,omitempty"`
-	File       string `json:"file,omitempty"`
-	Attempt    int    `json:"attempt,omitempty"`
-	ExitCode   int    `json:"exit_code,omitempty"`
-	Error      string `json:"error,omitempty"`
+	ID         string   `json:"id"`
+	Short      string   `json:"short"`
+	Trigger    string   `json:"trigger"`
+	Label      string   `json:"label"`
+	WorkingDir string   `json:"working_dir"`
+	Started    string   `json:"started"`
+	Status     string   `json:"status"`
+	Duration   int      `json:"duration,omitempty"`
+	PID        int      `json:"pid,omitempty"`
+	SessionID  string   `json:"session_id,omitempty"`
+	File       string   `json:"file,omitempty"`
+	Files      []string `json:"files,omitempty"` // batched runs; File is the first
+	Priority   int      `json:"priority,omitempty"`
+	Timeout    int      `json:"timeout,omitempty"` // limit in seconds, set on timed-out runs
+	Attempt    int      `json:"attempt,omitempty"`
+	RetryOf    string   `json:"retry_of,omitempty"`  // ID of the first attempt
+	RetryMax   int      `json:"retry_max,omitempty"` // attempt limit, 0 = unlimited
+	RetryAt    string   `json:"retry_at,omitempty"`  // when the next attempt starts
+	ExitCode   int      `json:"exit_code,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Pinned     bool     `json:"pinned,omitempty"` // kept by `workmode history prune`
+
+	// Denials counts the tool calls the run was refused permission for.
+	Denials int `json:"denials,omitempty"`
+	// Approval is "approved" once the refused calls were allowed and the
+	// session resumed (by ResumedBy), or "denied".
+	Approval  string `json:"approval,omitempty"`
+	ResumedBy string `json:"resumed_by,omitempty"`
+	// ResumeOf is set on a run that resumed a session with the refused
+	// calls allowed: the ID of that session, and the rules it allowed.
+	ResumeOf     string   `json:"resume_of,omitempty"`
+	AllowedTools []string `json:"allowed_tools,omitempty"`
+	// Parent is set on a run fired by another session's on_success,
+	// on_failure or on_complete (Chain), ChainDepth runs down the chain.
+	Parent     string `json:"parent,omitempty"`
+	Chain      string `json:"chain,omitempty"`
+	ChainDepth int    `json:"chain_depth,omitempty"`
+	// FanoutOf is set on a run for one item of a fan-out trigger's check
+	// output: the fan-out run, and the key the item is deduplicated by.
+	// The fan-out run itself records how many item runs it queued.
+	FanoutOf string `json:"fanout_of,omitempty"`
+	ItemKey  string `json:"item_key,omitempty"`
+	Fanout   int    `json:"fanout,omitempty"`
+	// Request is set on a webhook trigger's run: the ID of the request that
+	// started it, kept with its payload as logs/<id>.request and .payload.
+	Request string `json:"request,omitempty"`
+
+	// RunStats is recorded by workmode-run from the log's result event.
+	RunStats
+	// GitState is recorded by workmode-run when working_dir is in a git repo.
+	GitState
+}
+
+// RunStats is the cost, token usage and turn count claude reports in the
+// final result event of a run.
+type RunStats struct {
+	CostUSD             float64 `json:"cost_usd,omitempty"`
+	NumTurns            int     `json:"num_turns,omitempty"`
+	DurationAPIMs       int     `json:"duration_api_ms,omitempty"`
+	InputTokens         int     `json:"input_tokens,omitempty"`
+	OutputTokens        int     `json:"output_tokens,omitempty"`
+	CacheReadTokens     int     `json:"cache_read_input_tokens,omitempty"`
+	CacheCreationTokens int     `json:"cache_creation_input_tokens,omitempty"`
+	IsError             bool    `json:"is_error,omitempty"`
+}
+
+// HasStats reports whether any result data was recorded.
+func (r RunStats) HasStats() bool {
+	return r.CostUSD > 0 || r.NumTurns > 0 || r.TotalTokens() > 0
+}
+
+// PromptTokens returns input tokens including cache reads and writes.
+func (r RunStats) PromptTokens() int {
+	return r.InputTokens + r.CacheReadTokens + r.CacheCreationTokens
+}
+
+// TotalTokens returns prompt plus output tokens.
+func (r RunStats) TotalTokens() int {
+	return r.PromptTokens() + r.OutputTokens
+}
+
+// GitState is the state of a git working dir before and after a run. The
+// trees are snapshots of the whole working tree, untracked files included;
+// their diff is saved as logs/<id>.diff and the commits made during the run
+// as logs/<id>.commits.
+type GitState struct {
+	TreeBefore   string `json:"git_tree_before,omitempty"`
+	HeadBefore   string `json:"git_head_before,omitempty"`
+	BranchBefore string `json:"git_branch_before,omitempty"`
+	DirtyBefore  bool   `json:"git_dirty_before,omitempty"`
+	TreeAfter    string `json:"git_tree_after,omitempty"`
+	HeadAfter    string `json:"git_head_after,omitempty"`
+	BranchAfter  string `json:"git_branch_after,omitempty"`
+	DirtyAfter   bool   `json:"git_dirty_after,omitempty"`
+	FilesChanged int    `json:"git_files_changed,omitempty"`
+	Insertions   int    `json:"git_insertions,omitempty"`
+	Deletions    int    `json:"git_deletions,omitempty"`
+	Commits      int    `json:"git_commits,omitempty"`
+	// Reverted is when `workmode session revert` undid the run's changes.
+	Reverted string `json:"reverted,omitempty"`
+}
+
+// CanRevert reports whether `workmode session revert` has changes to undo.
+func (g GitState) CanRevert() bool {
+	return g.FilesChanged > 0 && g.Reverted == ""
+}
+
+// HasGit reports whether the run's working dir was snapshotted.
+func (g GitState) HasGit() bool {
+	return g.TreeBefore != ""
+}
+
+// DiffStat summarizes the run's changes: "3 files changed, +40 -12".
+func (g GitState) DiffStat() string {
+	if g.FilesChanged == 0 {
+		return "no changes"
+	}
+	s := "s"
+	if g.FilesChanged == 1 {
+		s = ""
+	}
+	return fmt.Sprintf("%d file%s changed, +%d -%d", g.FilesChanged, s, g.Insertions, g.Deletions)
+}
+
+// Describe renders the branch and HEAD before and after the run:
+// "main 1a2b3c4 → 5d6e7f8 (dirty before run)".
+func (g GitState) Describe() string {
+	branch := g.BranchBefore
+	if branch == "" {
+		branch = "(detached)"
+	}
+	head := shortHash(g.HeadBefore)
+	if head == "" {
+		head = "(no commits)"
+	}
+	if g.HeadAfter != "" && g.HeadAfter != g.HeadBefore {
+		head += " → " + shortHash(g.HeadAfter)
+	}
+	if g.BranchAfter != "" && g.BranchAfter != g.BranchBefore {
+		head += " on " + g.BranchAfter
+	}
+	s := branch + " " + head
+	if g.DirtyBefore {
+		s += " (dirty before run)"
+	}
+	return s
+}
+
+func shortHash(h string) string {
+	if len(h) > 7 {
+		return h[:7]
+	}
+	return h
 }
 
 // StartedTime parses the Started field as time.Time.
@@ -42,6 +196,43 @@ func (s Session) StartedTime() time.Time {
 	return t
 }
 
+// RetryRoot returns the ID of the first attempt of the run s belongs to.
+func (s Session) RetryRoot() string {
+	if s.RetryOf != "" {
+		return s.RetryOf
+	}
+	return s.ID
+}
+
+// Origin returns the run that started s, if any: the fan-out run it is an
+// item of, or the run it was chained from.
+func (s Session) Origin() string {
+	if s.FanoutOf != "" {
+		return s.FanoutOf
+	}
+	return s.Parent
+}
+
+// NeedsApproval reports whether the session stopped on, or was refused,
+// tool calls that are waiting for the user to approve or deny them.
+func (s Session) NeedsApproval() bool {
+	if s.Approval != "" || s.SessionID == "" {
+		return false
+	}
+	return s.Status == "stuck" || (s.Denials > 0 && s.Status != "running" && s.Status != "queued")
+}
+
+// InputFiles returns all files the session was started for.
+func (s Session) InputFiles() []string {
+	if len(s.Files) > 0 {
+		return s.Files
+	}
+	if s.File != "" {
+		return []string{s.File}
+	}
+	return nil
+}
+
 // Trigger represents a trigger from `workmode trigger list --json`.
 type Trigger struct {
 	Name        string `json:"name"`
@@ -52,6 +243,17 @@ type Trigger struct {
 	WorkingDir  string `json:"working_dir,omitempty"`
 	Cooldown    int    `json:"cooldown,omitempty"`
 	Check       string `json:"check,omitempty"`
+	// CheckFormat makes the trigger fan out: check prints JSON items
+	// (json_lines or a json array) and each gets its own run, told apart
+	// by the ItemKey field.
+	CheckFormat string `json:"check_format,omitempty"`
+	ItemKey     string `json:"item_key,omitempty"`
+	Priority    int    `json:"priority,omitempty"`
+	// Concurrency is what a fire does while the trigger is still running:
+	// "skip" (default), "queue_one", or "replace".
+	Concurrency string `json:"concurrency,omitempty"`
+	// Timeout is the run's time limit ("30m"); empty falls back to [general] timeout.
+	Timeout string `json:"timeout,omitempty"`
 
 	// Timer-specific
 	Interval string `json:"interval,omitempty"`
@@ -61,11 +263,28 @@ type Trigger struct {
 	Watch   string `json:"watch,omitempty"`
 	Pattern string `json:"pattern,omitempty"`
 	Settle  int    `json:"settle,omitempty"`
+	// BatchWindow gathers matching files for N seconds into one run.
+	BatchWindow int `json:"batch_window,omitempty"`
+
+	// Webhook-specific: the shared secret requests to /hooks/<name> prove
+	// they know, as a bearer token or an HMAC-SHA256 signature.
+	Secret string `json:"-"`
 
 	// Retry
-	Retry      string `json:"retry,omitempty"`
-	RetryMax   int    `json:"retry_max,omitempty"`
-	RetryDelay int    `json:"retry_delay,omitempty"`
+	Retry         string `json:"retry,omitempty"`
+	RetryMax      int    `json:"retry_max,omitempty"`
+	RetryDelay    int    `json:"retry_delay,omitempty"`
+	RetryBackoff  string `json:"retry_backoff,omitempty"`   // fixed | exponential
+	RetryDelayMax int    `json:"retry_delay_max,omitempty"` // cap for exponential backoff
+
+	// Follow-up triggers fired when a run ends, as comma-separated names:
+	// on success, on failure (after the last retry), or either way.
+	OnSuccess  string `json:"on_success,omitempty"`
+	OnFailure  string `json:"on_failure,omitempty"`
+	OnComplete string `json:"on_complete,omitempty"`
+
+	// Budget is the trigger's own spend limit; [general] sets a global one.
+	Budget
 }
 
 // Schedule returns a human-readable schedule string for the trigger.
@@ -82,39 +301,66 @@ func (t Trigger) Schedule() string {
 			s += " (" + t.Pattern + ")"
 		}
 		return s
+	case "webhook":
+		return "POST /hooks/" + t.Name
 	}
 	return ""
 }
 
+// NextRuns returns up to n upcoming fire times for a timer trigger.
+// Cron triggers are computed from now. Interval triggers are counted from
+// anchor (the trigger's most recent session start, or when its scheduler
+// started if that is later) and stepped forward past now, since fires
+// skipped by a check never reach history. A zero anchor counts from now.
+func (t Trigger) NextRuns(anchor, now time.Time, n int) []time.Time {
+	if t.Type != "timer" || n <= 0 {
+		return nil
+	}
+	if t.Cron != "" {
+		c, err := ParseCron(t.Cron)
+		if err != nil {
+			return nil
+		}
+		return c.NextN(now, n)
+	}
+
+	every, err := ParseInterval(t.Interval)
+	if err != nil {
+		return nil
+	}
+	if anchor.IsZero() {
+		anchor = now
+	}
+	next := anchor.Add(every)
+	if next.Before(now) {
+		next = next.Add(now.Sub(next).Truncate(every) + every)
+	}
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = next.Add(time.Duration(i) * every)
+	}
+	return times
+}
+
 // Config represents the output of `workmode config show --json`.
 type Config struct {
 	General struct {
 		StateDir    string `json:"state_dir"`
 		MaxParallel int    `json:"max_parallel"`
+		Timeout     string `json:"timeout,omitempty"`
+		// WebhookListen is where webhook triggers are served; empty means
+		// DefaultWebhookListen.
+		WebhookListen string `json:"webhook_listen,omitempty"`
+		Budget
 	} `json:"general"`
 	Triggers []Trigger `json:"triggers"`
 }
 
-// StreamEvent represents a single line from Claude's stream-json output.
-type StreamEvent struct {
-	Type    string       `json:"type"`
-	Message *MessageBody `json:"message,omitempty"`
-	Result  string       `json:"result,omitempty"`
-	// tool_use fields (flattened in stream-json)
-	ID    string `json:"id,omitempty"`
-	Name  string `json:"name,omitempty"`
-	Input any    `json:"input,omitempty"`
-}
-
-// MessageBody is the message field inside an assistant StreamEvent.
-type MessageBody struct {
-	Content []ContentBlock `json:"content"`
-}
-
-// ContentBlock represents a content block inside a message.
-type ContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
-	Name string `json:"name,omitempty"`
-	ID   string `json:"id,omitempty"`
+// DaemonState is the status file written by `workmode daemon` (daemon.json).
+type DaemonState struct {
+	PID     int    `json:"pid"`
+	Started string `json:"started"`
+	// Errors maps trigger names to problems the daemon hit while scheduling
+	// or watching them (bad cron, missing watch directory, ...).
+	Errors map[string]string `json:"errors,omitempty"`
 }
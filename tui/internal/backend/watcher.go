@@ -24,6 +24,7 @@ type WatchKind int
 const (
 	WatchHistory WatchKind = iota
 	WatchLog
+	WatchDaemon
 )
 
 // Sender can receive messages (matches *tea.Program).
@@ -100,6 +101,7 @@ func (w *Watcher) Close() error {
 
 func (w *Watcher) loop() {
 	historyFile := filepath.Base(w.client.HistoryPath())
+	daemonFile := filepath.Base(DaemonStatePath(w.client.StateDir()))
 
 	for {
 		select {
@@ -107,11 +109,17 @@ func (w *Watcher) loop() {
 			if !ok {
 				return
 			}
+			base := filepath.Base(event.Name)
+			if base == daemonFile {
+				// Renamed into place on write, removed on shutdown.
+				w.sender.Send(WatchMsg{Path: event.Name, Kind: WatchDaemon})
+				continue
+			}
+
 			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
 				continue
 			}
 
-			base := filepath.Base(event.Name)
 			if base == historyFile {
 				w.sender.Send(WatchMsg{Path: event.Name, Kind: WatchHistory})
 				continue
@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultWebhookListen is where webhook triggers are served unless
+// [general] webhook_listen says otherwise.
+const DefaultWebhookListen = "127.0.0.1:7878"
+
+// MaxWebhookPayload caps a webhook request's body. The payload ends up in
+// claude's prompt, which is a single command-line argument.
+const MaxWebhookPayload = 64 << 10
+
+// WebhookRequest is a POST to /hooks/<trigger>, as the webhook listener
+// spools it for workmode-run and the session keeps it (logs/<id>.request).
+type WebhookRequest struct {
+	ID          string            `json:"id"`
+	Trigger     string            `json:"trigger"`
+	Received    string            `json:"received"`
+	Remote      string            `json:"remote,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Size        int               `json:"size"`
+	Verified    string            `json:"verified"` // "token" or "hmac"
+	Header      map[string]string `json:"header,omitempty"`
+}
+
+// droppedHeaders are left out of a recorded request: credentials, and
+// transport details that say nothing about the request.
+var droppedHeaders = map[string]bool{
+	"Authorization":        true,
+	"Proxy-Authorization":  true,
+	"Cookie":               true,
+	"X-Workmode-Token":     true,
+	"X-Workmode-Signature": true,
+	"X-Hub-Signature":      true,
+	"X-Hub-Signature-256":  true,
+	"Accept-Encoding":      true,
+	"Connection":           true,
+	"Content-Length":       true,
+}
+
+// RecordHeader returns the headers of a webhook request worth keeping on
+// the session: all but credentials and transport details, one value each.
+func RecordHeader(h http.Header) map[string]string {
+	rec := make(map[string]string)
+	for k, v := range h {
+		if droppedHeaders[k] || len(v) == 0 {
+			continue
+		}
+		rec[k] = strings.Join(v, ", ")
+	}
+	return rec
+}
+
+// WebhookSpoolDir is where the listener leaves requests for workmode-run to
+// pick up, as <request-id>.json and <request-id>.payload.
+func WebhookSpoolDir(stateDir string) string {
+	return filepath.Join(stateDir, "webhooks")
+}
@@ -0,0 +1,225 @@
+// Package daemon fires workmode triggers from a long-running process, for
+// machines without a systemd user session (containers, CI boxes). It
+// replaces both the systemd timers and the inotifywait watcher service, and
+// serves webhook triggers.
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+)
+
+// maxSleep caps how long the loop waits between checks, so fire times are
+// re-evaluated after a suspend or a wall-clock jump.
+const maxSleep = time.Minute
+
+// pruneEvery is how often history retention runs, like the systemd
+// workmode-history.timer.
+const pruneEvery = 24 * time.Hour
+
+// Daemon schedules timer triggers, watches file triggers, serves webhook
+// triggers, and launches all of them through workmode-run.
+type Daemon struct {
+	client *backend.Client
+	runner *Runner
+	state  *stateFile
+	files  *fileEngine
+	hooks  *webhookServer
+
+	schedules map[string]*schedule
+	nextPrune time.Time
+}
+
+// schedule tracks the next fire time for one timer trigger.
+type schedule struct {
+	trigger backend.Trigger
+	cron    *backend.CronSchedule
+	every   time.Duration
+	next    time.Time
+}
+
+// New creates a daemon for the config and state dir resolved by client.
+func New(client *backend.Client) (*Daemon, error) {
+	runner, err := NewRunner(client.StateDir())
+	if err != nil {
+		return nil, err
+	}
+	state := newStateFile(client.StateDir())
+	return &Daemon{
+		client:    client,
+		runner:    runner,
+		state:     state,
+		files:     newFileEngine(runner, state),
+		hooks:     newWebhookServer(runner, state, client.StateDir()),
+		schedules: make(map[string]*schedule),
+	}, nil
+}
+
+// PIDPath returns the path of the daemon's pid file.
+func PIDPath(stateDir string) string {
+	return filepath.Join(stateDir, "daemon.pid")
+}
+
+// Run loops until ctx is cancelled, firing triggers as they come due and
+// reloading the config whenever it changes on disk.
+func (d *Daemon) Run(ctx context.Context) error {
+	pid, err := lockPIDFile(PIDPath(d.client.StateDir()))
+	if err != nil {
+		return err
+	}
+	defer pid.Release()
+	defer d.state.remove()
+	defer d.files.Close()
+	defer d.hooks.Close()
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	// Watch the config's directory so editors that replace the file are caught.
+	configPath := d.client.ConfigPath()
+	if err := fw.Add(filepath.Dir(configPath)); err != nil {
+		log.Printf("daemon: watch config: %v", err)
+	}
+
+	d.reload(time.Now())
+	d.nextPrune = time.Now()
+
+	for {
+		wait := maxSleep
+		if next, ok := d.nextFire(); ok {
+			if until := time.Until(next); until < wait {
+				wait = until
+			}
+		}
+		if until := time.Until(d.nextPrune); until < wait {
+			wait = until
+		}
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Printf("daemon: shutting down")
+			return nil
+
+		case now := <-timer.C:
+			d.fireDue(now)
+			if !d.nextPrune.After(now) {
+				d.runner.Prune()
+				d.nextPrune = now.Add(pruneEvery)
+			}
+
+		case event, ok := <-fw.Events:
+			timer.Stop()
+			if !ok {
+				return nil
+			}
+			if event.Name == configPath && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+				d.reload(time.Now())
+			}
+
+		case err, ok := <-fw.Errors:
+			timer.Stop()
+			if !ok {
+				return nil
+			}
+			log.Printf("daemon: watcher error: %v", err)
+		}
+	}
+}
+
+// reload re-reads the config. Schedules whose timing is unchanged keep their
+// pending fire time; new or changed ones start counting from now.
+func (d *Daemon) reload(now time.Time) {
+	cfg, err := backend.ReadConfigFile(d.client.ConfigPath())
+	if err != nil {
+		log.Printf("daemon: read config: %v", err)
+		return
+	}
+	d.state.reset()
+
+	next := make(map[string]*schedule)
+	for _, t := range cfg.Triggers {
+		if t.Type != "timer" {
+			continue
+		}
+		s, err := newSchedule(t, now)
+		if err != nil {
+			log.Printf("daemon: trigger %s: %v", t.Name, err)
+			d.state.setError(t.Name, err)
+			continue
+		}
+		if old, ok := d.schedules[t.Name]; ok && old.trigger.Cron == t.Cron && old.trigger.Interval == t.Interval {
+			s.next = old.next
+		}
+		next[t.Name] = s
+	}
+	d.schedules = next
+	log.Printf("daemon: loaded %d timer trigger(s)", len(next))
+
+	d.files.Reload(cfg.Triggers)
+	d.hooks.Reload(cfg.General.WebhookListen, cfg.Triggers)
+}
+
+func newSchedule(t backend.Trigger, now time.Time) (*schedule, error) {
+	s := &schedule{trigger: t}
+	if t.Cron != "" {
+		c, err := backend.ParseCron(t.Cron)
+		if err != nil {
+			return nil, err
+		}
+		s.cron = c
+	} else {
+		every, err := backend.ParseInterval(t.Interval)
+		if err != nil {
+			return nil, err
+		}
+		s.every = every
+	}
+	s.next = s.after(now)
+	if s.next.IsZero() {
+		return nil, fmt.Errorf("schedule never fires")
+	}
+	return s, nil
+}
+
+// after returns the next fire time following t.
+func (s *schedule) after(t time.Time) time.Time {
+	if s.cron != nil {
+		return s.cron.Next(t)
+	}
+	return t.Add(s.every)
+}
+
+func (d *Daemon) nextFire() (time.Time, bool) {
+	var earliest time.Time
+	for _, s := range d.schedules {
+		if earliest.IsZero() || s.next.Before(earliest) {
+			earliest = s.next
+		}
+	}
+	return earliest, !earliest.IsZero()
+}
+
+// fireDue launches every trigger whose fire time has passed. A trigger that
+// missed several fires (e.g. across a suspend) runs once, like Persistent=true.
+func (d *Daemon) fireDue(now time.Time) {
+	for name, s := range d.schedules {
+		if s.next.After(now) {
+			continue
+		}
+		log.Printf("daemon: firing %s", name)
+		d.runner.Launch(name)
+		s.next = s.after(now)
+	}
+}
@@ -0,0 +1,338 @@
+package daemon
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+)
+
+const (
+	// minSettle is the shortest wait before a file is considered finished.
+	// fsnotify has no close_write, so even settle = 0 waits this long for
+	// writes to stop.
+	minSettle = time.Second
+
+	// retryWatchInterval is how often missing watch directories are re-tried.
+	retryWatchInterval = 30 * time.Second
+)
+
+// fileEngine watches the directories of file triggers and launches a run
+// for each new file that matches a trigger's pattern.
+type fileEngine struct {
+	runner *Runner
+	state  *stateFile
+
+	mu       sync.Mutex
+	w        *fsnotify.Watcher
+	triggers []fileTrigger
+	pending  map[string]bool      // trigger+path currently settling
+	fired    map[string]fileStamp // trigger+path → stamp when last launched, until removed
+	batches  map[string][]string  // trigger → settled files awaiting batch_window
+}
+
+type fileTrigger struct {
+	trigger backend.Trigger
+	dir     string // expanded, cleaned watch directory
+	watched bool   // false while the directory is missing
+}
+
+// fileStamp identifies one version of a file's contents.
+type fileStamp struct {
+	size  int64
+	mtime int64 // UnixNano
+}
+
+func newFileEngine(runner *Runner, state *stateFile) *fileEngine {
+	return &fileEngine{
+		runner:  runner,
+		state:   state,
+		pending: make(map[string]bool),
+		fired:   make(map[string]fileStamp),
+		batches: make(map[string][]string),
+	}
+}
+
+// Reload replaces the watched set with the file triggers in triggers.
+func (e *fileEngine) Reload(triggers []backend.Trigger) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.w != nil {
+		e.w.Close()
+		e.w = nil
+	}
+	e.triggers = nil
+	keep := make(map[string]bool)
+	for _, t := range triggers {
+		if t.Type != "file" {
+			continue
+		}
+		if t.Watch == "" {
+			e.state.setError(t.Name, fmt.Errorf("no watch directory configured"))
+			continue
+		}
+		e.triggers = append(e.triggers, fileTrigger{
+			trigger: t,
+			dir:     filepath.Clean(backend.ExpandHome(t.Watch)),
+		})
+		keep[t.Name] = true
+	}
+	for key := range e.fired {
+		if name, _, _ := strings.Cut(key, "\x00"); !keep[name] {
+			delete(e.fired, key)
+		}
+	}
+	if len(e.triggers) == 0 {
+		return
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		for _, ft := range e.triggers {
+			e.state.setError(ft.trigger.Name, err)
+		}
+		return
+	}
+	e.w = w
+	e.addWatchesLocked()
+	go e.loop(w)
+
+	log.Printf("daemon: watching %d file trigger(s)", len(e.triggers))
+}
+
+// Close stops watching.
+func (e *fileEngine) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.w != nil {
+		e.w.Close()
+		e.w = nil
+	}
+}
+
+// addWatchesLocked adds (recursive) watches for every trigger directory not
+// yet watched, recording failures on the trigger.
+func (e *fileEngine) addWatchesLocked() {
+	for i := range e.triggers {
+		ft := &e.triggers[i]
+		if ft.watched {
+			continue
+		}
+		if err := addRecursive(e.w, ft.dir); err != nil {
+			e.state.setError(ft.trigger.Name, fmt.Errorf("watch %s: %w", ft.trigger.Watch, err))
+			continue
+		}
+		ft.watched = true
+		e.state.setError(ft.trigger.Name, nil)
+	}
+}
+
+func addRecursive(w *fsnotify.Watcher, root string) error {
+	info, err := os.Stat(root)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("directory does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory")
+	}
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable subdirectories are skipped, not fatal.
+			if p == root {
+				return err
+			}
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			return w.Add(p)
+		}
+		return nil
+	})
+}
+
+func (e *fileEngine) loop(w *fsnotify.Watcher) {
+	retry := time.NewTicker(retryWatchInterval)
+	defer retry.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				e.forget(event.Name)
+				continue
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			e.handle(w, event.Name)
+
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("daemon: file watcher error: %v", err)
+
+		case <-retry.C:
+			e.mu.Lock()
+			if e.w == w {
+				e.addWatchesLocked()
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+func (e *fileEngine) handle(w *fsnotify.Watcher, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if info.IsDir() {
+		// New subdirectory: watch it too, like inotifywait -r.
+		if err := addRecursive(w, path); err != nil {
+			log.Printf("daemon: watch %s: %v", path, err)
+		}
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// Like the systemd watcher, a file goes to the first trigger in config
+	// order that matches it, not to every one.
+	for _, ft := range e.triggers {
+		rel, err := filepath.Rel(ft.dir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		if !matchGlob(ft.trigger.Pattern, filepath.ToSlash(rel)) {
+			continue
+		}
+		key := ft.trigger.Name + "\x00" + path
+		if e.pending[key] {
+			return
+		}
+		// Ignore trailing events for a version we already launched.
+		if e.fired[key] == stampOf(info) {
+			return
+		}
+		e.pending[key] = true
+		go e.settle(ft.trigger, path, key)
+		return
+	}
+}
+
+// forget drops what is remembered about a removed or renamed path, and
+// about everything under it if it was a directory, so a file that comes
+// back with the same size and mtime fires again.
+func (e *fileEngine) forget(path string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key := range e.fired {
+		_, p, _ := strings.Cut(key, "\x00")
+		if p == path || strings.HasPrefix(p, path+string(filepath.Separator)) {
+			delete(e.fired, key)
+		}
+	}
+}
+
+// settle waits until the file's size and mtime stop changing across one
+// settle interval, then launches the run.
+func (e *fileEngine) settle(t backend.Trigger, path, key string) {
+	interval := time.Duration(t.Settle) * time.Second
+	if interval < minSettle {
+		interval = minSettle
+	}
+
+	var prev fileStamp
+	if info, err := os.Stat(path); err == nil {
+		prev = stampOf(info)
+	}
+	for {
+		time.Sleep(interval)
+		info, err := os.Stat(path)
+		if err != nil {
+			// File vanished before it settled (temp file, moved away).
+			e.mu.Lock()
+			delete(e.pending, key)
+			e.mu.Unlock()
+			return
+		}
+		cur := stampOf(info)
+		if cur == prev {
+			break
+		}
+		prev = cur
+	}
+
+	e.mu.Lock()
+	delete(e.pending, key)
+	e.fired[key] = prev
+	if t.BatchWindow > 0 {
+		e.addToBatchLocked(t, path)
+		e.mu.Unlock()
+		return
+	}
+	e.mu.Unlock()
+
+	log.Printf("daemon: firing %s for %s", t.Name, path)
+	e.runner.Launch(t.Name, "--file", path)
+}
+
+// addToBatchLocked queues a settled file for t. The first file of a batch
+// opens a batch_window; everything that settles inside it shares one run.
+func (e *fileEngine) addToBatchLocked(t backend.Trigger, path string) {
+	if _, open := e.batches[t.Name]; !open {
+		window := time.Duration(t.BatchWindow) * time.Second
+		time.AfterFunc(window, func() { e.flushBatch(t) })
+	}
+	e.batches[t.Name] = append(e.batches[t.Name], path)
+}
+
+// flushBatch launches one run for all files gathered for t. Files still
+// settling when the window closes are waited for, so a burst of copies is
+// not split across two runs.
+func (e *fileEngine) flushBatch(t backend.Trigger) {
+	e.mu.Lock()
+	prefix := t.Name + "\x00"
+	for key := range e.pending {
+		if strings.HasPrefix(key, prefix) {
+			e.mu.Unlock()
+			time.AfterFunc(minSettle, func() { e.flushBatch(t) })
+			return
+		}
+	}
+	files := e.batches[t.Name]
+	delete(e.batches, t.Name)
+	e.mu.Unlock()
+
+	if len(files) == 0 {
+		return
+	}
+	sort.Strings(files)
+	args := make([]string, 0, 2*len(files))
+	for _, f := range files {
+		args = append(args, "--file", f)
+	}
+	log.Printf("daemon: firing %s for %d file(s)", t.Name, len(files))
+	e.runner.Launch(t.Name, args...)
+}
+
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{size: info.Size(), mtime: info.ModTime().UnixNano()}
+}
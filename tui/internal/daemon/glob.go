@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether rel (a slash-separated path relative to the
+// watch directory) matches pattern. Patterns use path.Match syntax plus
+// "**" for any number of directories and brace sets such as "*.{png,jpg}".
+// A pattern without "/" is matched against the base name only, like the
+// inotifywait watcher.
+func matchGlob(pattern, rel string) bool {
+	if pattern == "" {
+		return true
+	}
+	for _, p := range expandBraces(pattern) {
+		if !strings.Contains(p, "/") {
+			if ok, _ := path.Match(p, path.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if matchSegments(strings.Split(p, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pat, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pat[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], name[0]); !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// expandBraces expands the first brace set in p (recursively, so nested and
+// repeated sets work). Unbalanced braces are left as literals, as are
+// escaped ones and those inside a character class.
+func expandBraces(p string) []string {
+	open, start, depth := -1, 0, 0
+	var alts []string
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			i++
+		case '[':
+			i = classEnd(p, i)
+		case '{':
+			if depth == 0 {
+				open, start, alts = i, i+1, nil
+			}
+			depth++
+		case ',':
+			if depth == 1 {
+				alts = append(alts, p[start:i])
+				start = i + 1
+			}
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				alts = append(alts, p[start:i])
+				var out []string
+				for _, a := range alts {
+					out = append(out, expandBraces(p[:open]+a+p[i+1:])...)
+				}
+				return out
+			}
+		}
+	}
+	return []string{p}
+}
+
+// classEnd returns the index of the "]" closing the character class that
+// starts at p[i], or i when it isn't closed.
+func classEnd(p string, i int) int {
+	for j := i + 1; j < len(p); j++ {
+		switch p[j] {
+		case '\\':
+			j++
+		case ']':
+			return j
+		}
+	}
+	return i
+}
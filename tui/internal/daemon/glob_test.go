@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"", "any/file.txt", true},
+
+		// Without a "/", only the base name is matched
+		{"*.md", "notes.md", true},
+		{"*.md", "deep/down/notes.md", true},
+		{"*.md", "notes.md.bak", false},
+		{"notes.md", "deep/notes.md", true},
+
+		// * stays within one path segment
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/sub/a.md", false},
+		{"*/a.md", "x/y/a.md", false},
+		{"docs/*", "docs/sub/a.md", false},
+
+		// ** is any number of directories, including none
+		{"**/*.md", "a.md", true},
+		{"**/*.md", "x/a.md", true},
+		{"**/*.md", "x/y/z/a.md", true},
+		{"**/*.md", "x/y/a.txt", false},
+		{"docs/**/*.md", "docs/a.md", true},
+		{"docs/**/*.md", "docs/x/y/a.md", true},
+		{"docs/**/*.md", "other/docs/a.md", false},
+		{"a/**", "a", true},
+		{"a/**", "a/b", true},
+		{"a/**", "a/b/c.txt", true},
+		{"a/**", "ab/c.txt", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/y/c", false},
+
+		// Brace sets
+		{"*.{jpg,png}", "a.jpg", true},
+		{"*.{jpg,png}", "photos/a.png", true},
+		{"*.{jpg,png}", "a.gif", false},
+		{"*.{jpg,png}", "a.{jpg,png}", false},
+		{"inbox/{a,b}/*.txt", "inbox/b/x.txt", true},
+		{"inbox/{a,b}/*.txt", "inbox/c/x.txt", false},
+		{"*.{tar.{gz,xz},zip}", "a.tar.xz", true},
+		{"*.{tar.{gz,xz},zip}", "a.zip", true},
+		{"*.{tar.{gz,xz},zip}", "a.tar", false},
+		{"{a,b}{1,2}.txt", "b1.txt", true},
+		{"{a,b}{1,2}.txt", "b3.txt", false},
+
+		// An unterminated brace is a literal
+		{"*.{jpg", "a.{jpg", true},
+		{"*.{jpg", "a.jpg", false},
+		{"a,b}.txt", "a,b}.txt", true},
+
+		// Character classes and escapes, from path.Match
+		{"[ab].md", "a.md", true},
+		{"[ab].md", "c.md", false},
+		{"[^ab].md", "c.md", true},
+		{"[^ab].md", "a.md", false},
+		{"report-[0-9].csv", "report-7.csv", true},
+		{"report-[0-9].csv", "report-x.csv", false},
+		{"?.md", "a.md", true},
+		{"?.md", "ab.md", false},
+		{`\*.md`, "*.md", true},
+		{`\*.md`, "a.md", false},
+		{`\{a,b\}.txt`, "{a,b}.txt", true},
+		{`\{a,b\}.txt`, "a.txt", false},
+		{"[{,}].txt", "{.txt", true},
+		{"[{,}].txt", "a.txt", false},
+		{"{[ab],c}.md", "b.md", true},
+
+		// A malformed pattern matches nothing
+		{"[a-.md", "a.md", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.rel); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"*.md", []string{"*.md"}},
+		{"*.{jpg,png}", []string{"*.jpg", "*.png"}},
+		{"{a,b}/{1,2}", []string{"a/1", "a/2", "b/1", "b/2"}},
+		{"x{a,{b,c}d}y", []string{"xay", "xbdy", "xcdy"}},
+		{"{a,,b}", []string{"a", "", "b"}},
+		{"{a,b", []string{"{a,b"}},
+		{"a}{b,c}", []string{"a}b", "a}c"}},
+		{"{a,{b,c}", []string{"{a,{b,c}"}},
+		{`\{a,b}`, []string{`\{a,b}`}},
+		{`{a\,b,c}`, []string{`a\,b`, "c"}},
+		{"[{]{a,b}", []string{"[{]a", "[{]b"}},
+	}
+	for _, tt := range tests {
+		if got := expandBraces(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandBraces(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
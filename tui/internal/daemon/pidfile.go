@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// pidFile is daemon.pid, held under an exclusive flock for as long as the
+// daemon runs so a second one can't start alongside it. The lock, not the
+// pid in the file, is what says a daemon is running: the file stays behind
+// when the daemon exits.
+type pidFile struct {
+	f *os.File
+}
+
+// lockPIDFile takes the pid file at path and writes our pid into it. It
+// fails if another daemon holds the lock; a pid left in the file by one
+// that died is overwritten.
+func lockPIDFile(path string) (*pidFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open pid file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("daemon already running (pid %d)", readPID(path))
+		}
+		return nil, fmt.Errorf("lock pid file: %w", err)
+	}
+
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write pid file: %w", err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write pid file: %w", err)
+	}
+	return &pidFile{f: f}, nil
+}
+
+// Release empties the pid file and drops the lock. The file isn't removed:
+// a starter that opened it before the unlink could lock the orphaned inode
+// while another creates a new one, and both would run.
+func (p *pidFile) Release() {
+	p.f.Truncate(0)
+	p.f.Close()
+}
+
+// readPID returns the pid recorded in a pid file, or 0.
+func readPID(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(string(bytes.TrimSpace(data)))
+	return pid
+}
@@ -0,0 +1,109 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// runnerName is the bash runner that performs dedup, logging and notifications.
+const runnerName = "workmode-run"
+
+// cliName is the main CLI, used for maintenance commands.
+const cliName = "workmode"
+
+// Runner launches workmode-run for a trigger without waiting on the session.
+type Runner struct {
+	bin    string
+	cli    string // empty if workmode was not found
+	logDir string
+}
+
+// NewRunner locates workmode-run next to the running executable, falling
+// back to $PATH.
+func NewRunner(stateDir string) (*Runner, error) {
+	bin, err := findBin(runnerName)
+	if err != nil {
+		return nil, err
+	}
+	cli, _ := findBin(cliName)
+	return &Runner{bin: bin, cli: cli, logDir: filepath.Join(stateDir, "logs")}, nil
+}
+
+func findBin(name string) (string, error) {
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		candidate := filepath.Join(filepath.Dir(exe), name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	bin, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%s not found next to executable or in PATH", name)
+	}
+	return bin, nil
+}
+
+// Launch starts workmode-run --trigger <name> [args...] in its own process
+// group. Runner output is appended to logs/<trigger>.log, mirroring the
+// systemd service units.
+func (r *Runner) Launch(trigger string, args ...string) {
+	if err := os.MkdirAll(r.logDir, 0o755); err != nil {
+		log.Printf("daemon: %v", err)
+		return
+	}
+	out, err := os.OpenFile(filepath.Join(r.logDir, trigger+".log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("daemon: open runner log: %v", err)
+		return
+	}
+
+	cmd := exec.Command(r.bin, append([]string{"--trigger", trigger}, args...)...)
+	cmd.Env = append(os.Environ(), "CLAUDECODE=") // unset CLAUDECODE for nested claude calls
+	r.start(cmd, out, trigger)
+}
+
+// Prune runs `workmode history prune` in the background, mirroring the
+// daily systemd history timer. Output is appended to logs/history.log.
+func (r *Runner) Prune() {
+	if r.cli == "" {
+		log.Printf("daemon: %s not found, skipping history prune", cliName)
+		return
+	}
+	if err := os.MkdirAll(r.logDir, 0o755); err != nil {
+		log.Printf("daemon: %v", err)
+		return
+	}
+	out, err := os.OpenFile(filepath.Join(r.logDir, "history.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("daemon: open history log: %v", err)
+		return
+	}
+	r.start(exec.Command(r.cli, "history", "prune", "--quiet"), out, "history prune")
+}
+
+// start runs cmd in its own process group and closes out once it exits.
+func (r *Runner) start(cmd *exec.Cmd, out *os.File, what string) {
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		out.Close()
+		log.Printf("daemon: launch %s: %v", what, err)
+		return
+	}
+
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			log.Printf("daemon: %s exited: %v", what, err)
+		}
+		out.Close()
+	}()
+}
@@ -0,0 +1,81 @@
+package daemon
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+)
+
+// stateFile publishes the daemon's pid and per-trigger problems to
+// daemon.json so the TUI can show them.
+type stateFile struct {
+	mu    sync.Mutex
+	path  string
+	state backend.DaemonState
+}
+
+func newStateFile(stateDir string) *stateFile {
+	return &stateFile{
+		path: backend.DaemonStatePath(stateDir),
+		state: backend.DaemonState{
+			PID:     os.Getpid(),
+			Started: time.Now().Format(time.RFC3339),
+			Errors:  map[string]string{},
+		},
+	}
+}
+
+// setError records a problem for a trigger, or clears it when err is nil.
+func (s *stateFile) setError(trigger string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		if _, ok := s.state.Errors[trigger]; !ok {
+			return
+		}
+		delete(s.state.Errors, trigger)
+	} else {
+		if s.state.Errors[trigger] == err.Error() {
+			return
+		}
+		s.state.Errors[trigger] = err.Error()
+	}
+	s.writeLocked()
+}
+
+// reset clears all errors (called before a config reload re-checks them).
+func (s *stateFile) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Errors = map[string]string{}
+	s.writeLocked()
+}
+
+func (s *stateFile) remove() {
+	os.Remove(s.path)
+}
+
+// writeLocked replaces the file atomically so readers never see a partial write.
+func (s *stateFile) writeLocked() {
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return
+	}
+	tmp := s.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		log.Printf("daemon: %v", err)
+		return
+	}
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.Printf("daemon: write state: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		log.Printf("daemon: write state: %v", err)
+	}
+}
@@ -0,0 +1,402 @@
+package daemon
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+)
+
+const (
+	// deliveryTTL is how long a delivery ID is remembered to turn away
+	// replays of the same request.
+	deliveryTTL = 24 * time.Hour
+
+	// timestampSkew is how far a signed X-Workmode-Timestamp may be from
+	// the server's clock.
+	timestampSkew = 5 * time.Minute
+)
+
+// webhookServer serves POST /hooks/<trigger> for webhook triggers. Each
+// verified request is spooled for workmode-run, which picks it up with
+// --request <id>.
+type webhookServer struct {
+	runner   *Runner
+	state    *stateFile // nil when serving on its own, without the daemon
+	spoolDir string
+
+	mu       sync.Mutex
+	triggers map[string]backend.Trigger
+	listen   string
+	srv      *http.Server
+	seen     map[string]time.Time // trigger+delivery ID → when it was received
+}
+
+func newWebhookServer(runner *Runner, state *stateFile, stateDir string) *webhookServer {
+	return &webhookServer{
+		runner:   runner,
+		state:    state,
+		spoolDir: backend.WebhookSpoolDir(stateDir),
+		triggers: make(map[string]backend.Trigger),
+		seen:     make(map[string]time.Time),
+	}
+}
+
+// ServeWebhooks serves the config's webhook triggers until ctx is
+// cancelled. It's what the workmode-webhook systemd service runs; `workmode
+// daemon` serves them itself.
+func ServeWebhooks(ctx context.Context, client *backend.Client) error {
+	runner, err := NewRunner(client.StateDir())
+	if err != nil {
+		return err
+	}
+	cfg, err := backend.ReadConfigFile(client.ConfigPath())
+	if err != nil {
+		return err
+	}
+	w := newWebhookServer(runner, nil, client.StateDir())
+	w.Reload(cfg.General.WebhookListen, cfg.Triggers)
+	if !w.serving() {
+		return errors.New("no webhook triggers to serve")
+	}
+	<-ctx.Done()
+	w.Close()
+	return nil
+}
+
+// Reload serves the webhook triggers in triggers. The listener is
+// restarted only when listen changes, and stopped when no webhook
+// triggers are left.
+func (w *webhookServer) Reload(listen string, triggers []backend.Trigger) {
+	if listen == "" {
+		listen = backend.DefaultWebhookListen
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.triggers = make(map[string]backend.Trigger)
+	for _, t := range triggers {
+		if t.Type != "webhook" {
+			continue
+		}
+		if t.Secret == "" {
+			w.setError(t.Name, errors.New("no secret configured"))
+			continue
+		}
+		w.triggers[t.Name] = t
+	}
+	if len(w.triggers) == 0 {
+		w.closeLocked()
+		return
+	}
+	if w.srv != nil && w.listen == listen {
+		return
+	}
+
+	w.closeLocked()
+	ln, err := listenWebhooks(listen)
+	if err != nil {
+		for name := range w.triggers {
+			w.setError(name, err)
+		}
+		return
+	}
+	srv := &http.Server{
+		Handler:           w,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+	}
+	w.srv = srv
+	w.listen = listen
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("webhook: %v", err)
+		}
+	}()
+
+	log.Printf("webhook: serving %d trigger(s) on %s", len(w.triggers), listen)
+}
+
+// Close stops the listener.
+func (w *webhookServer) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeLocked()
+}
+
+func (w *webhookServer) closeLocked() {
+	if w.srv != nil {
+		w.srv.Close()
+		w.srv = nil
+	}
+}
+
+func (w *webhookServer) serving() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.srv != nil
+}
+
+// setError records a problem with a trigger in daemon.json, or only logs
+// it when serving without the daemon.
+func (w *webhookServer) setError(trigger string, err error) {
+	log.Printf("webhook: trigger %s: %v", trigger, err)
+	if w.state != nil {
+		w.state.setError(trigger, err)
+	}
+}
+
+// listenWebhooks listens on a unix socket for an absolute path, a ~ path
+// or unix:<path>, and on TCP otherwise.
+func listenWebhooks(listen string) (net.Listener, error) {
+	path, isUnix := strings.CutPrefix(listen, "unix:")
+	if !isUnix && !strings.HasPrefix(listen, "/") && !strings.HasPrefix(listen, "~") {
+		if host, _, err := net.SplitHostPort(listen); err == nil {
+			if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+				log.Printf("webhook: %s is reachable from other machines; only the trigger secrets keep them out", listen)
+			}
+		}
+		return net.Listen("tcp", listen)
+	}
+	if !isUnix {
+		path = listen
+	}
+	path = backend.ExpandHome(path)
+
+	// A socket left behind by a server that died is removed; one that
+	// still answers belongs to a running server.
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is already in use", path)
+		}
+		os.Remove(path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// ServeHTTP launches a run of the trigger a request is posted to, once it
+// has proven it knows the trigger's secret. It answers 202 with the
+// request's ID, which the run records.
+func (w *webhookServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.URL.Path, "/hooks/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		http.NotFound(rw, r)
+		return
+	}
+	w.mu.Lock()
+	t, ok := w.triggers[name]
+	w.mu.Unlock()
+	if !ok {
+		httpError(rw, http.StatusNotFound, "no webhook trigger %q", name)
+		return
+	}
+	if r.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		httpError(rw, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, backend.MaxWebhookPayload))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpError(rw, http.StatusRequestEntityTooLarge, "payload is over %d bytes", tooLarge.Limit)
+		} else {
+			httpError(rw, http.StatusBadRequest, "read payload: %v", err)
+		}
+		return
+	}
+	verified, ok := verifyWebhook(t.Secret, r.Header, body)
+	if !ok {
+		log.Printf("webhook: %s: rejected a request from %s without a valid token or signature", name, r.RemoteAddr)
+		httpError(rw, http.StatusUnauthorized, "missing or invalid token or signature")
+		return
+	}
+	if err := checkTimestamp(r.Header, time.Now()); err != nil {
+		log.Printf("webhook: %s: rejected a request from %s: %v", name, r.RemoteAddr, err)
+		httpError(rw, http.StatusUnauthorized, "%v", err)
+		return
+	}
+	delivery := deliveryID(r.Header)
+	if !w.firstDelivery(name, delivery, time.Now()) {
+		log.Printf("webhook: %s: rejected a replay of %s from %s", name, delivery, r.RemoteAddr)
+		httpError(rw, http.StatusConflict, "delivery %s was already received", delivery)
+		return
+	}
+
+	req := backend.WebhookRequest{
+		ID:          newRequestID(),
+		Trigger:     name,
+		Received:    time.Now().Format(time.RFC3339),
+		Remote:      r.RemoteAddr,
+		ContentType: r.Header.Get("Content-Type"),
+		Size:        len(body),
+		Verified:    verified,
+		Header:      backend.RecordHeader(r.Header),
+	}
+	if err := w.spool(req, body); err != nil {
+		w.forgetDelivery(name, delivery)
+		log.Printf("webhook: %s: %v", name, err)
+		httpError(rw, http.StatusInternalServerError, "can't save the request")
+		return
+	}
+	log.Printf("webhook: %s: request %s (%d bytes), launching", name, req.ID, len(body))
+	w.runner.Launch(name, "--request", req.ID)
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(rw).Encode(map[string]string{"trigger": name, "request": req.ID})
+}
+
+// spool leaves a request and its payload for workmode-run, which moves
+// them next to the session's log.
+func (w *webhookServer) spool(req backend.WebhookRequest, body []byte) error {
+	if err := os.MkdirAll(w.spoolDir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(w.spoolDir, req.ID+".payload"), body, 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(w.spoolDir, req.ID+".json"), append(data, '\n'), 0o600)
+}
+
+// firstDelivery records a request's delivery ID and reports whether it is
+// new, forgetting IDs older than deliveryTTL. Requests without one always
+// pass.
+func (w *webhookServer) firstDelivery(trigger, delivery string, now time.Time) bool {
+	if delivery == "" {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, at := range w.seen {
+		if now.Sub(at) > deliveryTTL {
+			delete(w.seen, key)
+		}
+	}
+	key := trigger + "\x00" + delivery
+	if _, ok := w.seen[key]; ok {
+		return false
+	}
+	w.seen[key] = now
+	return true
+}
+
+// forgetDelivery lets a delivery be retried after the server failed it.
+func (w *webhookServer) forgetDelivery(trigger, delivery string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.seen, trigger+"\x00"+delivery)
+}
+
+// deliveryID returns what identifies one delivery of a request, so a replay
+// of it can be told apart from a new one: the sender's delivery ID, or the
+// signature of a timestamped request, which covers the timestamp.
+func deliveryID(h http.Header) string {
+	for _, header := range []string{"X-GitHub-Delivery", "X-Workmode-Delivery"} {
+		if id := h.Get(header); id != "" {
+			return id
+		}
+	}
+	if h.Get("X-Workmode-Timestamp") != "" {
+		return h.Get("X-Workmode-Signature")
+	}
+	return ""
+}
+
+// checkTimestamp rejects a signed request whose X-Workmode-Timestamp is
+// more than timestampSkew away from now.
+func checkTimestamp(h http.Header, now time.Time) error {
+	ts := h.Get("X-Workmode-Timestamp")
+	if ts == "" || h.Get("X-Workmode-Signature") == "" {
+		return nil
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid X-Workmode-Timestamp %q (want Unix seconds)", ts)
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > timestampSkew || d < -timestampSkew {
+		return fmt.Errorf("X-Workmode-Timestamp is more than %v from the server's clock", timestampSkew)
+	}
+	return nil
+}
+
+// verifyWebhook checks that a request knows the trigger's secret, and how
+// it showed it: "hmac" for an HMAC-SHA256 of the body in
+// X-Workmode-Signature or X-Hub-Signature-256 ("sha256=<hex>", as GitHub
+// sends it), "token" for the secret itself as a bearer token or in
+// X-Workmode-Token. With X-Workmode-Timestamp, X-Workmode-Signature signs
+// "<timestamp>.<body>" instead of the body. An empty secret verifies
+// nothing.
+func verifyWebhook(secret string, h http.Header, body []byte) (string, bool) {
+	if secret == "" {
+		return "", false
+	}
+	for _, header := range []string{"X-Workmode-Signature", "X-Hub-Signature-256"} {
+		sig := h.Get(header)
+		if sig == "" {
+			continue
+		}
+		got, err := hex.DecodeString(strings.TrimPrefix(sig, "sha256="))
+		mac := hmac.New(sha256.New, []byte(secret))
+		if ts := h.Get("X-Workmode-Timestamp"); ts != "" && header == "X-Workmode-Signature" {
+			mac.Write([]byte(ts + "."))
+		}
+		mac.Write(body)
+		return "hmac", err == nil && hmac.Equal(got, mac.Sum(nil))
+	}
+	token := h.Get("X-Workmode-Token")
+	if bearer, ok := strings.CutPrefix(h.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	if token == "" {
+		return "", false
+	}
+	return "token", subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// newRequestID returns an ID like req-1712345678-3fa2c1, after the
+// wm-<epoch>-<pid> session IDs.
+func newRequestID() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return fmt.Sprintf("req-%d-%s", time.Now().Unix(), hex.EncodeToString(b))
+}
+
+func httpError(rw http.ResponseWriter, code int, format string, args ...any) {
+	http.Error(rw, fmt.Sprintf(format, args...), code)
+}
@@ -0,0 +1,192 @@
+package daemon
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+)
+
+const testSecret = "change-me"
+
+func sign(secret, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func headers(kv ...string) http.Header {
+	h := make(http.Header)
+	for i := 0; i+1 < len(kv); i += 2 {
+		h.Set(kv[i], kv[i+1])
+	}
+	return h
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := `{"ref":"v1.4.2"}`
+	ts := "1760000000"
+	tests := []struct {
+		name    string
+		secret  string
+		header  http.Header
+		wantVia string
+		wantOK  bool
+	}{
+		{"hmac with prefix", testSecret, headers("X-Workmode-Signature", "sha256="+sign(testSecret, body)), "hmac", true},
+		{"hmac without prefix", testSecret, headers("X-Workmode-Signature", sign(testSecret, body)), "hmac", true},
+		{"hmac with the wrong secret", testSecret, headers("X-Workmode-Signature", sign("other", body)), "hmac", false},
+		{"github signature", testSecret, headers("X-Hub-Signature-256", "sha256="+sign(testSecret, body)), "hmac", true},
+		{"github signature ignores the timestamp", testSecret, headers(
+			"X-Workmode-Timestamp", ts,
+			"X-Hub-Signature-256", "sha256="+sign(testSecret, body),
+		), "hmac", true},
+		{"timestamped signature", testSecret, headers(
+			"X-Workmode-Timestamp", ts,
+			"X-Workmode-Signature", "sha256="+sign(testSecret, ts+"."+body),
+		), "hmac", true},
+		{"body-only signature with a timestamp", testSecret, headers(
+			"X-Workmode-Timestamp", ts,
+			"X-Workmode-Signature", "sha256="+sign(testSecret, body),
+		), "hmac", false},
+		{"timestamped signature without the timestamp", testSecret, headers(
+			"X-Workmode-Signature", "sha256="+sign(testSecret, ts+"."+body),
+		), "hmac", false},
+		{"malformed hex", testSecret, headers("X-Workmode-Signature", "sha256=zz"+sign(testSecret, body)[2:]), "hmac", false},
+		{"bearer token", testSecret, headers("Authorization", "Bearer "+testSecret), "token", true},
+		{"workmode token", testSecret, headers("X-Workmode-Token", testSecret), "token", true},
+		{"wrong token", testSecret, headers("X-Workmode-Token", "change-m"), "token", false},
+		{"basic auth", testSecret, headers("Authorization", "Basic "+testSecret), "", false},
+		{"no credentials", testSecret, headers(), "", false},
+		{"empty secret, empty-key signature", "", headers("X-Workmode-Signature", sign("", body)), "", false},
+		{"empty secret, empty token", "", headers("Authorization", "Bearer "), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			via, ok := verifyWebhook(tt.secret, tt.header, []byte(body))
+			if via != tt.wantVia || ok != tt.wantOK {
+				t.Errorf("verifyWebhook = %q, %v; want %q, %v", via, ok, tt.wantVia, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckTimestamp(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	at := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr bool
+	}{
+		{"no timestamp", headers("X-Workmode-Signature", "ab"), false},
+		{"now", headers("X-Workmode-Timestamp", at(0), "X-Workmode-Signature", "ab"), false},
+		{"at the past bound", headers("X-Workmode-Timestamp", at(-timestampSkew), "X-Workmode-Signature", "ab"), false},
+		{"past the bound", headers("X-Workmode-Timestamp", at(-timestampSkew-time.Second), "X-Workmode-Signature", "ab"), true},
+		{"at the future bound", headers("X-Workmode-Timestamp", at(timestampSkew), "X-Workmode-Signature", "ab"), false},
+		{"beyond the future bound", headers("X-Workmode-Timestamp", at(timestampSkew+time.Second), "X-Workmode-Signature", "ab"), true},
+		{"not a number", headers("X-Workmode-Timestamp", "yesterday", "X-Workmode-Signature", "ab"), true},
+		// Without a signature covering it the timestamp means nothing
+		{"unsigned", headers("X-Workmode-Timestamp", "yesterday", "X-Workmode-Token", testSecret), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkTimestamp(tt.header, now); (err != nil) != tt.wantErr {
+				t.Errorf("checkTimestamp = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeliveryID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{"github", headers("X-GitHub-Delivery", "d1", "X-Workmode-Delivery", "d2"), "d1"},
+		{"workmode", headers("X-Workmode-Delivery", "d2"), "d2"},
+		{"timestamped signature", headers("X-Workmode-Timestamp", "1", "X-Workmode-Signature", "sha256=ab"), "sha256=ab"},
+		{"signature without a timestamp", headers("X-Workmode-Signature", "sha256=ab"), ""},
+		{"token", headers("X-Workmode-Token", testSecret), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveryID(tt.header); got != tt.want {
+				t.Errorf("deliveryID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstDelivery(t *testing.T) {
+	w := newWebhookServer(nil, nil, t.TempDir())
+	now := time.Unix(1760000000, 0)
+
+	steps := []struct {
+		trigger, delivery string
+		at                time.Duration
+		want              bool
+	}{
+		{"deploy", "d1", 0, true},
+		{"deploy", "d1", time.Minute, false},
+		{"other", "d1", time.Minute, true}, // IDs are per trigger
+		{"deploy", "", 0, true},
+		{"deploy", "", 0, true},
+		{"deploy", "d1", deliveryTTL, false},
+		{"deploy", "d1", deliveryTTL + time.Second, true},
+	}
+	for i, s := range steps {
+		if got := w.firstDelivery(s.trigger, s.delivery, now.Add(s.at)); got != s.want {
+			t.Errorf("step %d: firstDelivery(%s, %q) = %v, want %v", i, s.trigger, s.delivery, got, s.want)
+		}
+	}
+
+	w.forgetDelivery("deploy", "d1")
+	if !w.firstDelivery("deploy", "d1", now.Add(deliveryTTL+2*time.Second)) {
+		t.Error("a forgotten delivery was refused")
+	}
+}
+
+func TestServeHTTPReplay(t *testing.T) {
+	dir := t.TempDir()
+	w := newWebhookServer(&Runner{bin: "/bin/true", logDir: filepath.Join(dir, "logs")}, nil, dir)
+	w.triggers["deploy"] = backend.Trigger{Name: "deploy", Type: "webhook", Secret: testSecret}
+
+	post := func() int {
+		r := httptest.NewRequest(http.MethodPost, "/hooks/deploy", strings.NewReader(`{}`))
+		r.Header.Set("X-GitHub-Delivery", "d1")
+		r.Header.Set("X-Hub-Signature-256", "sha256="+sign(testSecret, `{}`))
+		rec := httptest.NewRecorder()
+		w.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	// A spool dir that can't be created fails the request, and the sender's
+	// retry of the same delivery is let through.
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w.spoolDir = filepath.Join(blocked, "webhooks")
+	if code := post(); code != http.StatusInternalServerError {
+		t.Fatalf("with a broken spool dir: %d, want 500", code)
+	}
+
+	w.spoolDir = backend.WebhookSpoolDir(dir)
+	if code := post(); code != http.StatusAccepted {
+		t.Fatalf("retry after a failure: %d, want 202", code)
+	}
+	if code := post(); code != http.StatusConflict {
+		t.Fatalf("replay: %d, want 409", code)
+	}
+}
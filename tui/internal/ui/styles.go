@@ -45,6 +45,10 @@ var (
 	StyleError         lipgloss.Style
 	StylePreviewBorder lipgloss.Style
 	StyleSelected      lipgloss.Style
+	StyleDiffAdd       lipgloss.Style
+	StyleDiffDel       lipgloss.Style
+	StyleMatch         lipgloss.Style
+	StyleMatchCurrent  lipgloss.Style
 )
 
 func initStyles() {
@@ -87,6 +91,20 @@ func initStyles() {
 	StyleSelected = lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color(T.Accent))
+
+	StyleDiffAdd = lipgloss.NewStyle().
+		Foreground(ColorGreen)
+
+	StyleDiffDel = lipgloss.NewStyle().
+		Foreground(ColorRed)
+
+	StyleMatch = lipgloss.NewStyle().
+		Reverse(true)
+
+	StyleMatchCurrent = lipgloss.NewStyle().
+		Reverse(true).
+		Bold(true).
+		Foreground(ColorYellow)
 }
 
 // StatusIcon returns a plain indicator character for a session status.
@@ -106,6 +124,16 @@ func StatusIcon(status string) string {
 		return "■"
 	case "killed":
 		return "†"
+	case "queued":
+		return "◷"
+	case "cancelled":
+		return "⊘"
+	case "skipped":
+		return "↷"
+	case "replaced":
+		return "⇥"
+	case "timeout":
+		return "⧗"
 	default:
 		return " "
 	}
@@ -118,11 +146,11 @@ func StatusColor(status string) color.Color {
 		return ColorGreen
 	case "running":
 		return ColorBlue
-	case "error":
+	case "error", "timeout":
 		return ColorRed
-	case "stuck":
+	case "stuck", "queued":
 		return ColorYellow
-	case "stopped", "killed":
+	case "stopped", "killed", "cancelled", "skipped", "replaced":
 		return ColorDim
 	default:
 		return ColorDim
@@ -149,6 +177,47 @@ func FormatDuration(secs int) string {
 	return fmt.Sprintf("%dh%dm", h, m)
 }
 
+// FormatCost formats a USD amount, e.g. "$0.42". Zero is blank.
+func FormatCost(usd float64) string {
+	if usd <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+// FormatTokens formats a token count compactly, e.g. "950", "12.3k", "1.2M".
+// Zero is blank.
+func FormatTokens(n int) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1000000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	}
+}
+
+// FormatNextRun formats an upcoming fire time relative to now,
+// e.g. "in 12m (08:45 Mon)".
+func FormatNextRun(t time.Time) string {
+	d := time.Until(t)
+	var rel string
+	switch {
+	case d < time.Minute:
+		rel = "<1m"
+	case d < time.Hour:
+		rel = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		rel = FormatDuration(int(d.Seconds()))
+	default:
+		rel = fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+	return fmt.Sprintf("in %s (%s)", rel, t.Local().Format("15:04 Mon"))
+}
+
 // FormatTime formats an ISO 8601 timestamp into a short time string.
 func FormatTime(iso string) string {
 	t, err := time.Parse(time.RFC3339, iso)
@@ -0,0 +1,291 @@
+package approvals
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+	"github.com/olivoil/workmode/tui/internal/ui"
+)
+
+// maxContentLines caps the file content shown for a refused Write.
+const maxContentLines = 200
+
+// Model is the full-screen queue of tool calls sessions were refused
+// permission for: a list of pending requests above the selected one in
+// full.
+type Model struct {
+	detail    viewport.Model
+	approvals []backend.Approval
+	cursor    int
+	top       int // first listed request
+	width     int
+	height    int
+	active    bool
+	status    string
+}
+
+// New creates a new approvals view model.
+func New() Model {
+	vp := viewport.New(viewport.WithWidth(80), viewport.WithHeight(10))
+	return Model{detail: vp}
+}
+
+// SetSize updates the view dimensions.
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.detail.SetWidth(w - 2)
+	m.detail.SetHeight(max(h-m.listHeight()-3, 3))
+	m.scroll()
+}
+
+// Show opens the view.
+func (m *Model) Show() {
+	m.active = true
+	m.status = ""
+	m.cursor, m.top = 0, 0
+	m.render()
+	m.detail.GotoTop()
+}
+
+// Hide closes the view.
+func (m *Model) Hide() {
+	m.active = false
+	m.status = ""
+}
+
+// Active returns whether the view is visible.
+func (m *Model) Active() bool {
+	return m.active
+}
+
+// SetApprovals updates the pending requests, keeping the selected one
+// selected while it is still pending.
+func (m *Model) SetApprovals(approvals []backend.Approval) {
+	var key string
+	if a := m.Selected(); a != nil {
+		key = approvalKey(*a)
+	}
+	m.approvals = approvals
+	m.cursor = min(m.cursor, max(len(approvals)-1, 0))
+	for i, a := range approvals {
+		if approvalKey(a) == key {
+			m.cursor = i
+			break
+		}
+	}
+	m.SetSize(m.width, m.height)
+	m.render()
+	if a := m.Selected(); a == nil || approvalKey(*a) != key {
+		m.detail.GotoTop()
+	}
+}
+
+// SetStatus shows a line above the list, e.g. the outcome of an action.
+func (m *Model) SetStatus(status string) {
+	m.status = status
+}
+
+// Selected returns the selected request, if any.
+func (m *Model) Selected() *backend.Approval {
+	if m.cursor >= 0 && m.cursor < len(m.approvals) {
+		return &m.approvals[m.cursor]
+	}
+	return nil
+}
+
+// Rules returns the rules that allow every refused call of a session.
+func (m *Model) Rules(sessionID string) []string {
+	var rules []string
+	for _, a := range m.approvals {
+		if a.Session.ID == sessionID && a.Denial.ToolName != "" {
+			rules = append(rules, a.Denial.Rule())
+		}
+	}
+	return rules
+}
+
+// Update handles messages: j/k move between requests, other keys scroll
+// the selected one.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+	if msg, ok := msg.(tea.KeyPressMsg); ok {
+		switch msg.String() {
+		case "down", "j":
+			m.move(1)
+			return m, nil
+		case "up", "k":
+			m.move(-1)
+			return m, nil
+		case "home", "g":
+			m.move(-len(m.approvals))
+			return m, nil
+		case "end", "G":
+			m.move(len(m.approvals))
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.detail, cmd = m.detail.Update(msg)
+	return m, cmd
+}
+
+// View renders the view.
+func (m Model) View() string {
+	var b strings.Builder
+	n := len(m.approvals)
+	title := ui.StyleHeader.Render(" approvals ") + "  "
+	if n == 0 {
+		title += ui.StyleDim.Render("No tool calls waiting for approval")
+	} else {
+		title += fmt.Sprintf("%d pending request%s", n, plural(n))
+	}
+	if m.status != "" {
+		title += ui.StyleDim.Render("   " + m.status)
+	}
+	b.WriteString(ansi.Truncate(title, m.width, "…") + "\n")
+
+	rows := m.listHeight()
+	for i := m.top; i < m.top+rows && i < n; i++ {
+		b.WriteString(m.listLine(i) + "\n")
+	}
+	b.WriteString(ui.StyleDim.Render(strings.Repeat("─", max(m.width, 0))) + "\n")
+	b.WriteString(m.detail.View())
+	return b.String()
+}
+
+// listHeight is the number of list rows: every request, up to a third of
+// the view.
+func (m *Model) listHeight() int {
+	return max(min(len(m.approvals), m.height/3), 1)
+}
+
+func (m *Model) move(delta int) {
+	c := max(min(m.cursor+delta, len(m.approvals)-1), 0)
+	if c == m.cursor {
+		return
+	}
+	m.cursor = c
+	m.scroll()
+	m.render()
+	m.detail.GotoTop()
+}
+
+// scroll keeps the cursor within the listed rows.
+func (m *Model) scroll() {
+	rows := m.listHeight()
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+rows {
+		m.top = m.cursor - rows + 1
+	}
+	m.top = max(min(m.top, len(m.approvals)-rows), 0)
+}
+
+// listLine renders request i as "› g-ab12  Bash   git push origin main".
+func (m *Model) listLine(i int) string {
+	a := m.approvals[i]
+	tool, target := a.Denial.ToolName, a.Denial.Target()
+	if tool == "" {
+		tool, target = "?", "stuck, no refused call in the log"
+	}
+	target = strings.Join(strings.Fields(target), " ")
+	line := fmt.Sprintf("%-14s %-10s %s", a.Session.Short, tool, target)
+	if i == m.cursor {
+		return ansi.Truncate(ui.StyleSelected.Render("› "+line), m.width, "…")
+	}
+	return ansi.Truncate("  "+line, m.width, "…")
+}
+
+// render fills the detail pane with the selected request.
+func (m *Model) render() {
+	a := m.Selected()
+	if a == nil {
+		m.detail.SetContentLines([]string{ui.StyleDim.Render("Sessions that stop on a refused tool call are listed here.")})
+		return
+	}
+	sess := a.Session
+	lines := []string{
+		ui.StyleAccent.Render(sess.Short) + "  " + ui.StyleDim.Render(sess.Status+" · "+sess.Trigger+" · "+ui.FormatTime(sess.Started)),
+	}
+	if sess.WorkingDir != "" {
+		lines = append(lines, ui.StyleDim.Render(sess.WorkingDir))
+	}
+	lines = append(lines, "")
+
+	d := a.Denial
+	if d.ToolName == "" {
+		lines = append(lines,
+			"The run stopped for permission but its log names no refused tool call.",
+			ui.StyleDim.Render("ctrl+r resumes it in Claude to see what it needs; x dismisses it."),
+		)
+		m.detail.SetContentLines(lines)
+		return
+	}
+
+	in := d.Args()
+	lines = append(lines, ui.StyleHeader.Render(d.ToolName))
+	if in.Description != "" {
+		lines = append(lines, ui.StyleDim.Render(in.Description))
+	}
+	for _, l := range splitLines(d.Target()) {
+		lines = append(lines, "  "+l)
+	}
+	switch {
+	case in.Content != "":
+		lines = append(lines, "", ui.StyleDim.Render("Content:"))
+		content := splitLines(in.Content)
+		cut := 0
+		if len(content) > maxContentLines {
+			cut = len(content) - maxContentLines
+			content = content[:maxContentLines]
+		}
+		for _, l := range content {
+			lines = append(lines, ui.StyleDiffAdd.Render("  + "+l))
+		}
+		if cut > 0 {
+			lines = append(lines, ui.StyleDim.Render(fmt.Sprintf("  … %d more line%s", cut, plural(cut))))
+		}
+	case in.OldString != "" || in.NewString != "":
+		lines = append(lines, "")
+		for _, l := range splitLines(in.OldString) {
+			lines = append(lines, ui.StyleDiffDel.Render("  - "+l))
+		}
+		for _, l := range splitLines(in.NewString) {
+			lines = append(lines, ui.StyleDiffAdd.Render("  + "+l))
+		}
+	}
+
+	lines = append(lines, "", ui.StyleDim.Render("Approving allows:"), "  "+ui.StyleAccent.Render(d.Rule()))
+	m.detail.SetContentLines(lines)
+}
+
+// approvalKey identifies a request across reloads.
+func approvalKey(a backend.Approval) string {
+	return a.Session.ID + "\x00" + a.Denial.ToolUseID
+}
+
+// splitLines splits text into lines, dropping a trailing newline and
+// expanding tabs, which the viewport doesn't measure.
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\t", "    "), "\n")
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
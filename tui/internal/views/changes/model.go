@@ -0,0 +1,219 @@
+package changes
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+	"github.com/olivoil/workmode/tui/internal/ui"
+)
+
+// maxWriteLines caps the content shown for a Write.
+const maxWriteLines = 400
+
+// Model is the full-screen list of the files a session changed, with each
+// edit shown as a diff.
+type Model struct {
+	viewport viewport.Model
+	session  *backend.Session
+	width    int
+	height   int
+	active   bool
+
+	files   []backend.FileChange
+	offsets []int // first content line of each file
+	events  int   // log events the files were extracted from
+}
+
+// New creates a new changes view model.
+func New() Model {
+	vp := viewport.New(viewport.WithWidth(80), viewport.WithHeight(24))
+	return Model{viewport: vp}
+}
+
+// SetSize updates the view dimensions.
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.viewport.SetWidth(w - 2)
+	m.viewport.SetHeight(h)
+}
+
+// Show opens the view for a session.
+func (m *Model) Show(session *backend.Session, events []backend.StreamEvent) {
+	m.session = session
+	m.active = true
+	m.events = -1
+	m.SetEvents(events)
+	m.viewport.GotoTop()
+}
+
+// SetEvents updates the view from the session's log, keeping the scroll
+// position unless the view was at the bottom.
+func (m *Model) SetEvents(events []backend.StreamEvent) {
+	if m.session == nil || len(events) == m.events {
+		return
+	}
+	atBottom := m.events >= 0 && m.viewport.AtBottom()
+	m.events = len(events)
+	m.files = backend.ExtractFileChanges(events)
+	m.render()
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// Hide closes the view.
+func (m *Model) Hide() {
+	m.active = false
+	m.session = nil
+	m.files = nil
+	m.offsets = nil
+}
+
+// Active returns whether the view is visible.
+func (m *Model) Active() bool {
+	return m.active
+}
+
+// Session returns the session being viewed.
+func (m *Model) Session() *backend.Session {
+	return m.session
+}
+
+// Update handles messages: tab and shift+tab jump between files, other
+// keys scroll.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+	if msg, ok := msg.(tea.KeyPressMsg); ok {
+		switch msg.String() {
+		case "tab":
+			m.jump(1)
+			return m, nil
+		case "shift+tab":
+			m.jump(-1)
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the view.
+func (m Model) View() string {
+	return m.viewport.View()
+}
+
+// jump scrolls to the next (dir 1) or previous (dir -1) file.
+func (m *Model) jump(dir int) {
+	top := m.viewport.YOffset()
+	if dir > 0 {
+		for _, off := range m.offsets {
+			if off > top {
+				m.viewport.SetYOffset(off)
+				return
+			}
+		}
+		return
+	}
+	for i := len(m.offsets) - 1; i >= 0; i-- {
+		if m.offsets[i] < top {
+			m.viewport.SetYOffset(m.offsets[i])
+			return
+		}
+	}
+}
+
+func (m *Model) render() {
+	sess := m.session
+	var lines []string
+	lines = append(lines, ui.StyleAccent.Render(sess.Short)+"  "+ui.StyleDim.Render(sess.Status))
+	if sess.WorkingDir != "" {
+		lines = append(lines, ui.StyleDim.Render(sess.WorkingDir))
+	}
+	summary := "No file changes in the log"
+	if n := len(m.files); n > 0 {
+		summary = fmt.Sprintf("%d file%s changed", n, plural(n))
+	}
+	lines = append(lines,
+		summary+ui.StyleDim.Render(" (Write, Edit, MultiEdit and NotebookEdit calls; Bash changes are not tracked)"),
+		ui.StyleDim.Render("────────────────────────────────────────"),
+	)
+
+	m.offsets = m.offsets[:0]
+	for _, f := range m.files {
+		lines = append(lines, "")
+		m.offsets = append(m.offsets, len(lines))
+		lines = append(lines, ui.StyleHeader.Render(f.Path))
+		for i, e := range f.Edits {
+			lines = append(lines, editLines(e, i, len(f.Edits))...)
+		}
+	}
+	m.viewport.SetContentLines(lines)
+}
+
+// editLines renders one edit: a heading, then removed and added lines.
+func editLines(e backend.FileEdit, i, n int) []string {
+	heading := e.Tool
+	if n > 1 {
+		heading = fmt.Sprintf("%s %d/%d", e.Tool, i+1, n)
+	}
+	if e.Cell != "" {
+		heading += " cell " + e.Cell
+	}
+	switch {
+	case e.Failed:
+		heading = ui.StyleError.Render(heading + " (failed)")
+	case e.Pending:
+		heading = ui.StyleDim.Render(heading + " (running)")
+	default:
+		heading = ui.StyleDim.Render(heading)
+	}
+	lines := []string{"  " + heading}
+
+	if e.Tool == backend.ToolWrite {
+		content := splitLines(e.Content)
+		cut := 0
+		if len(content) > maxWriteLines {
+			cut = len(content) - maxWriteLines
+			content = content[:maxWriteLines]
+		}
+		for _, l := range content {
+			lines = append(lines, ui.StyleDiffAdd.Render("  + "+l))
+		}
+		if cut > 0 {
+			lines = append(lines, ui.StyleDim.Render(fmt.Sprintf("  … %d more line%s", cut, plural(cut))))
+		}
+		return lines
+	}
+	for _, l := range splitLines(e.OldString) {
+		lines = append(lines, ui.StyleDiffDel.Render("  - "+l))
+	}
+	for _, l := range splitLines(e.NewString) {
+		lines = append(lines, ui.StyleDiffAdd.Render("  + "+l))
+	}
+	return lines
+}
+
+// splitLines splits text into lines, dropping a trailing newline and
+// expanding tabs, which the viewport doesn't measure.
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\t", "    "), "\n")
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
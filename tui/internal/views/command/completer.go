@@ -61,6 +61,23 @@ var commands = map[string]cmdEntry{
 		{"resume", "Resume session in Claude"},
 		{"stop", "Stop running session"},
 		{"kill", "Kill running session"},
+		{"cancel", "Cancel queued run"},
+		{"priority", "Reprioritize queued run"},
+		{"pin", "Keep session through pruning"},
+		{"unpin", "Let session be pruned again"},
+		{"export", "Export transcript (md/html/json)"},
+		{"revert", "Undo changes to the git working dir"},
+		{"approve", "Allow refused tool calls and resume"},
+		{"deny", "Leave refused tool calls denied"},
+	}},
+	"budget": {desc: "Spend budgets", subs: []subEntry{
+		{"list", "Show spend against limits"},
+		{"set", "Set spend limits"},
+		{"override", "Let the next run start despite its budget"},
+	}},
+	"history": {desc: "Compact and prune history", subs: []subEntry{
+		{"compact", "One line per session"},
+		{"prune", "Apply retention, delete old logs"},
 	}},
 	"config": {desc: "Manage configuration", subs: []subEntry{
 		{"show", "Show current config"},
@@ -114,8 +131,13 @@ func (c *Completer) Complete(input string) []Candidate {
 			if sub == "run" || sub == "show" || sub == "enable" || sub == "disable" {
 				return c.dynamicCandidates(c.triggerNames, prefix, "trigger")
 			}
+		case "budget":
+			if sub == "set" || sub == "override" {
+				return c.dynamicCandidates(c.triggerNames, prefix, "trigger")
+			}
 		case "session":
-			if sub == "logs" || sub == "tail" || sub == "resume" || sub == "stop" || sub == "kill" {
+			if sub == "logs" || sub == "tail" || sub == "resume" || sub == "stop" || sub == "kill" || sub == "cancel" || sub == "priority" ||
+				sub == "pin" || sub == "unpin" || sub == "export" || sub == "revert" || sub == "approve" || sub == "deny" {
 				return c.dynamicCandidates(c.sessionIDs, prefix, "session")
 			}
 		}
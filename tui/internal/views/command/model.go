@@ -127,6 +127,16 @@ func (m *Model) Focus() tea.Cmd {
 	return m.input.Focus()
 }
 
+// FocusWith activates the command line with text already typed, for
+// commands the user only needs to check or adjust before running.
+func (m *Model) FocusWith(text string) tea.Cmd {
+	cmd := m.Focus()
+	m.input.SetValue(text)
+	m.input.CursorEnd()
+	m.updateCandidates()
+	return cmd
+}
+
 // Blur deactivates the command line input.
 func (m *Model) Blur() {
 	m.focused = false
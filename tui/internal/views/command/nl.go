@@ -89,22 +89,20 @@ func (p *NLProcess) Kill() {
 
 func extractText(event backend.StreamEvent) string {
 	switch event.Type {
-	case "assistant":
-		if event.Message != nil {
-			var parts []string
-			for _, c := range event.Message.Content {
-				switch c.Type {
-				case "text":
-					parts = append(parts, c.Text)
-				case "tool_use":
-					parts = append(parts, fmt.Sprintf("[tool: %s]", c.Name))
-				}
+	case backend.EventAssistant:
+		var parts []string
+		for _, b := range event.Blocks() {
+			switch b := b.(type) {
+			case backend.TextBlock:
+				parts = append(parts, b.Text)
+			case backend.ToolUseBlock:
+				parts = append(parts, fmt.Sprintf("[tool: %s]", b.Name))
 			}
-			return strings.Join(parts, "\n")
 		}
-	case "tool_use":
+		return strings.Join(parts, "\n")
+	case backend.EventToolUse:
 		return fmt.Sprintf("[tool: %s]", event.Name)
-	case "result":
+	case backend.EventResult:
 		return event.Result
 	}
 	return ""
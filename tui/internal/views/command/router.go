@@ -23,7 +23,9 @@ var commandTree = map[string][]string{
 	"off":     nil,
 	"status":  nil,
 	"trigger": {"list", "show", "run", "enable", "disable"},
-	"session": {"list", "logs", "tail", "resume", "stop", "kill"},
+	"session": {"list", "logs", "tail", "resume", "stop", "kill", "cancel", "priority", "pin", "unpin", "export", "revert", "approve", "deny"},
+	"budget":  {"list", "set", "override"},
+	"history": {"compact", "prune"},
 	"config":  {"show", "edit", "validate", "apply", "path"},
 	"help":    nil,
 	"version": nil,
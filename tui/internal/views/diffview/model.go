@@ -0,0 +1,191 @@
+package diffview
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+	"github.com/olivoil/workmode/tui/internal/ui"
+)
+
+// Model is the full-screen git diff of a session's working dir, from the
+// snapshots workmode-run takes before and after the run.
+type Model struct {
+	viewport viewport.Model
+	session  *backend.Session
+	width    int
+	height   int
+	active   bool
+
+	offsets []int // first line of each file's diff
+}
+
+// New creates a new diff view model.
+func New() Model {
+	vp := viewport.New(viewport.WithWidth(80), viewport.WithHeight(24))
+	return Model{viewport: vp}
+}
+
+// SetSize updates the view dimensions.
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.viewport.SetWidth(w - 2)
+	m.viewport.SetHeight(h)
+}
+
+// Show opens the view for a session with its saved diff and commits.
+func (m *Model) Show(session *backend.Session, diff string, commits []string) {
+	m.session = session
+	m.active = true
+	m.render(diff, commits)
+	m.viewport.GotoTop()
+}
+
+// Hide closes the view.
+func (m *Model) Hide() {
+	m.active = false
+	m.session = nil
+	m.offsets = nil
+}
+
+// Active returns whether the view is visible.
+func (m *Model) Active() bool {
+	return m.active
+}
+
+// Session returns the session being viewed.
+func (m *Model) Session() *backend.Session {
+	return m.session
+}
+
+// Update handles messages: tab and shift+tab jump between files, other
+// keys scroll.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+	if msg, ok := msg.(tea.KeyPressMsg); ok {
+		switch msg.String() {
+		case "tab":
+			m.jump(1)
+			return m, nil
+		case "shift+tab":
+			m.jump(-1)
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the view.
+func (m Model) View() string {
+	return m.viewport.View()
+}
+
+// jump scrolls to the next (dir 1) or previous (dir -1) file.
+func (m *Model) jump(dir int) {
+	top := m.viewport.YOffset()
+	if dir > 0 {
+		for _, off := range m.offsets {
+			if off > top {
+				m.viewport.SetYOffset(off)
+				return
+			}
+		}
+		return
+	}
+	for i := len(m.offsets) - 1; i >= 0; i-- {
+		if m.offsets[i] < top {
+			m.viewport.SetYOffset(m.offsets[i])
+			return
+		}
+	}
+}
+
+func (m *Model) render(diff string, commits []string) {
+	sess := m.session
+	lines := []string{
+		ui.StyleAccent.Render(sess.Short) + "  " + ui.StyleDim.Render(sess.Status),
+		ui.StyleDim.Render(sess.WorkingDir),
+		sess.Describe(),
+		sess.DiffStat(),
+	}
+	if len(commits) > 0 {
+		lines = append(lines, "", ui.StyleHeader.Render(fmt.Sprintf("%d commit%s", len(commits), plural(len(commits)))))
+		for _, c := range commits {
+			hash, subject, _ := strings.Cut(c, " ")
+			lines = append(lines, "  "+ui.StyleAccent.Render(hash)+" "+subject)
+		}
+	}
+	lines = append(lines, ui.StyleDim.Render("────────────────────────────────────────"))
+
+	m.offsets = m.offsets[:0]
+	if diff == "" {
+		lines = append(lines, ui.StyleDim.Render("No changes to the working tree."))
+	}
+	header, binary := false, false
+	for _, l := range splitLines(diff) {
+		switch {
+		case strings.HasPrefix(l, "diff --git "):
+			header, binary = true, false
+			lines = append(lines, "")
+			m.offsets = append(m.offsets, len(lines))
+			lines = append(lines, ui.StyleHeader.Render(diffPath(l)))
+		case binary:
+			// base85 data
+		case l == "GIT binary patch":
+			binary = true
+			lines = append(lines, ui.StyleDim.Render("(binary file changed)"))
+		case strings.HasPrefix(l, "@@"):
+			header = false
+			lines = append(lines, ui.StyleAccent.Render(l))
+		case header:
+			// index, mode, rename and ---/+++ lines
+			lines = append(lines, ui.StyleDim.Render(l))
+		case strings.HasPrefix(l, "+"):
+			lines = append(lines, ui.StyleDiffAdd.Render(l))
+		case strings.HasPrefix(l, "-"):
+			lines = append(lines, ui.StyleDiffDel.Render(l))
+		default:
+			lines = append(lines, l)
+		}
+	}
+	m.viewport.SetContentLines(lines)
+}
+
+// diffPath returns the file of a "diff --git a/x b/y" line, as "x → y"
+// for a rename.
+func diffPath(l string) string {
+	a, b, ok := strings.Cut(strings.TrimPrefix(l, "diff --git "), " b/")
+	if !ok {
+		return l
+	}
+	a = strings.TrimPrefix(a, "a/")
+	if a != b {
+		return a + " → " + b
+	}
+	return b
+}
+
+// splitLines splits text into lines, dropping a trailing newline and
+// expanding tabs, which the viewport doesn't measure.
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\t", "    "), "\n")
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -0,0 +1,189 @@
+package logview
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/olivoil/workmode/tui/internal/backend"
+	"github.com/olivoil/workmode/tui/internal/ui"
+)
+
+const (
+	maxInputLines  = 40 // lines of tool input shown in an open block
+	maxResultLines = 12 // lines of tool result shown in an open block
+)
+
+// renderEntry renders one transcript entry as content lines.
+func renderEntry(e backend.TranscriptEntry, open, selected bool) []string {
+	var lines []string
+	if e.IsTool() {
+		lines = renderTool(e, open, selected)
+	} else {
+		lines = strings.Split(strings.TrimRight(e.Text, "\n"), "\n")
+	}
+	if e.Subagent {
+		for i, l := range lines {
+			lines[i] = "  " + l
+		}
+	}
+	return lines
+}
+
+// renderTool renders a tool call: a header line with the tool name, its main
+// argument and the result status, then, when open, the input and result.
+func renderTool(e backend.TranscriptEntry, open, selected bool) []string {
+	cursor, marker := "  ", "▸"
+	if selected {
+		cursor = ui.StyleSelected.Render("›") + " "
+	}
+	if open {
+		marker = "▾"
+	}
+
+	status := ui.StyleDim.Render("…")
+	if r := e.Result; r != nil {
+		if r.IsError {
+			status = ui.StyleError.Render("✗")
+		} else {
+			status = ui.StyleDiffAdd.Render("✓")
+		}
+	}
+
+	name := ui.StyleAccent.Render(e.Tool.Name)
+	if selected {
+		name = ui.StyleSelected.Render(e.Tool.Name)
+	}
+	header := cursor + ui.StyleDim.Render(marker) + " " + name
+	if summary := e.Tool.Summary(); summary != "" {
+		header += "  " + summary
+	}
+	header += "  " + status
+
+	lines := []string{header}
+	if !open {
+		return lines
+	}
+
+	bar := ui.StyleDim.Render("    │ ")
+	for _, l := range toolInputLines(*e.Tool) {
+		lines = append(lines, bar+l)
+	}
+	lines = append(lines, ui.StyleDim.Render("    ├─ result"))
+	for _, l := range toolResultLines(e.Result) {
+		lines = append(lines, bar+l)
+	}
+	return lines
+}
+
+// toolInputLines shows what a tool was asked to do: the command for Bash, a
+// diff for edits, the content for writes, and the raw input otherwise.
+func toolInputLines(t backend.ToolUseBlock) []string {
+	in := t.Args()
+	var lines []string
+	switch t.Name {
+	case "Bash":
+		if in.Description != "" {
+			lines = append(lines, ui.StyleDim.Render("# "+in.Description))
+		}
+		for i, l := range splitLines(in.Command) {
+			if i == 0 {
+				l = "$ " + l
+			} else {
+				l = "  " + l
+			}
+			lines = append(lines, l)
+		}
+	case "Edit":
+		lines = append(lines, ui.StyleDim.Render(in.FilePath))
+		lines = append(lines, diffLines(in.OldString, in.NewString)...)
+	case "MultiEdit":
+		lines = append(lines, ui.StyleDim.Render(in.FilePath))
+		for i, edit := range in.Edits {
+			if i > 0 {
+				lines = append(lines, ui.StyleDim.Render("⋯"))
+			}
+			lines = append(lines, diffLines(edit.OldString, edit.NewString)...)
+		}
+	case "Write":
+		lines = append(lines, ui.StyleDim.Render(in.FilePath))
+		for _, l := range splitLines(in.Content) {
+			lines = append(lines, ui.StyleDiffAdd.Render("+ "+l))
+		}
+	default:
+		lines = rawInputLines(t.Input)
+	}
+	return truncateLines(lines, maxInputLines)
+}
+
+// diffLines renders an Edit's replacement as removed and added lines.
+func diffLines(before, after string) []string {
+	var lines []string
+	for _, l := range splitLines(before) {
+		lines = append(lines, ui.StyleDiffDel.Render("- "+l))
+	}
+	for _, l := range splitLines(after) {
+		lines = append(lines, ui.StyleDiffAdd.Render("+ "+l))
+	}
+	return lines
+}
+
+// rawInputLines pretty-prints a tool input that has no dedicated rendering.
+func rawInputLines(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, raw, "", "  "); err != nil {
+		return splitLines(string(raw))
+	}
+	return splitLines(b.String())
+}
+
+// toolResultLines shows the start of a tool's result.
+func toolResultLines(r *backend.ToolResultBlock) []string {
+	if r == nil {
+		return []string{ui.StyleDim.Render("(no result yet)")}
+	}
+	text := r.Content.Text()
+	if text == "" {
+		if len(r.Content) > 0 {
+			return []string{ui.StyleDim.Render(fmt.Sprintf("(%d non-text block(s))", len(r.Content)))}
+		}
+		return []string{ui.StyleDim.Render("(empty)")}
+	}
+	lines := splitLines(text)
+	if r.IsError {
+		for i, l := range lines {
+			lines[i] = ui.StyleError.Render(l)
+		}
+	}
+	return truncateLines(lines, maxResultLines)
+}
+
+// truncateLines keeps the first n lines and notes how many were cut.
+func truncateLines(lines []string, n int) []string {
+	if len(lines) <= n {
+		return lines
+	}
+	more := len(lines) - n
+	return append(lines[:n:n], ui.StyleDim.Render(fmt.Sprintf("… %d more line%s", more, plural(more))))
+}
+
+// splitLines splits text into lines, dropping a trailing newline and
+// expanding tabs, which the viewport doesn't measure.
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\t", "    "), "\n")
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
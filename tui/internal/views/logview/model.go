@@ -1,6 +1,8 @@
 package logview
 
 import (
+	"strings"
+
 	tea "charm.land/bubbletea/v2"
 	"charm.land/bubbles/v2/viewport"
 
@@ -15,6 +17,21 @@ type Model struct {
 	width    int
 	height   int
 	active   bool
+
+	header     []string            // session lines above the log
+	lines      []string            // rendered content, before search highlights
+	transcript *backend.Transcript // the log, read as text and tool calls
+	entries    [][]string          // rendered lines of each entry; nil when stale
+	offsets    []int               // first content line of each entry
+	events     int                 // how many log events the transcript covers
+
+	// Tool blocks start closed. openAll flips the default; open holds the
+	// blocks toggled away from it.
+	openAll  bool
+	open     map[int]bool
+	selected int // entry index of the selected tool block, -1 for none
+
+	search search
 }
 
 // New creates a new log view model.
@@ -22,6 +39,8 @@ func New() Model {
 	vp := viewport.New(viewport.WithWidth(80), viewport.WithHeight(24))
 	return Model{
 		viewport: vp,
+		selected: -1,
+		search:   newSearch(),
 	}
 }
 
@@ -30,23 +49,49 @@ func (m *Model) SetSize(w, h int) {
 	m.width = w
 	m.height = h
 	m.viewport.SetWidth(w - 2)
-	m.viewport.SetHeight(h)
+	m.search.input.SetWidth(w - 4)
+	m.resize()
+}
+
+// resize fits the viewport under the search bar, when it is shown.
+func (m *Model) resize() {
+	h := m.height
+	if m.search.active() {
+		h--
+	}
+	m.viewport.SetHeight(max(h, 1))
 }
 
 // Show opens the log view for a session.
 func (m *Model) Show(session *backend.Session, events []backend.StreamEvent) {
 	m.session = session
 	m.active = true
+	m.openAll = false
+	m.open = make(map[int]bool)
+	m.selected = -1
 	m.setContent(session, events)
 }
 
-// UpdateLog refreshes the log content (for live tail).
-func (m *Model) UpdateLog(events []backend.StreamEvent) {
+// AppendLog updates the content for live tail. events is the whole log,
+// the last added of which are new; when the view already shows the ones
+// before, only the new events are added to the transcript.
+func (m *Model) AppendLog(events []backend.StreamEvent, added int) {
 	if m.session == nil {
 		return
 	}
+	if len(events) < m.events && added < len(events) {
+		return // an older read arriving late
+	}
 	atBottom := m.viewport.AtBottom()
-	m.setContent(m.session, events)
+	if m.events > 0 && m.events == len(events)-added {
+		for _, i := range m.transcript.Add(events[m.events:]) {
+			m.entries[i] = nil // a tool result arrived
+		}
+		m.events = len(events)
+		m.refresh()
+	} else {
+		m.setContent(m.session, events)
+	}
 	if atBottom {
 		m.viewport.GotoBottom()
 	}
@@ -54,8 +99,15 @@ func (m *Model) UpdateLog(events []backend.StreamEvent) {
 
 // Hide closes the log view.
 func (m *Model) Hide() {
+	m.ClearSearch()
 	m.active = false
 	m.session = nil
+	m.header = nil
+	m.lines = nil
+	m.transcript = nil
+	m.entries = nil
+	m.offsets = nil
+	m.events = 0
 }
 
 // Active returns whether the log view is visible.
@@ -73,6 +125,36 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	if !m.active {
 		return m, nil
 	}
+	if msg, ok := msg.(tea.KeyPressMsg); ok {
+		if m.search.typing {
+			return m, m.updateSearch(msg)
+		}
+		switch msg.String() {
+		case "/":
+			return m, m.startSearch()
+		case "n":
+			m.nextMatch(1)
+			return m, nil
+		case "N":
+			m.nextMatch(-1)
+			return m, nil
+		case "tab":
+			m.selectTool(1)
+			return m, nil
+		case "shift+tab":
+			m.selectTool(-1)
+			return m, nil
+		case "enter":
+			m.toggleSelected()
+			return m, nil
+		case "e":
+			m.setAllOpen(true)
+			return m, nil
+		case "c":
+			m.setAllOpen(false)
+			return m, nil
+		}
+	}
 	var cmd tea.Cmd
 	m.viewport, cmd = m.viewport.Update(msg)
 	return m, cmd
@@ -80,9 +162,25 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 // View renders the log view.
 func (m Model) View() string {
+	if m.search.active() {
+		return m.searchBar() + "\n" + m.viewport.View()
+	}
 	return m.viewport.View()
 }
 
+// timeoutNote explains how a timed-out run was ended.
+func timeoutNote(sess *backend.Session) string {
+	note := "Timed out"
+	if sess.Timeout > 0 {
+		note += " after " + ui.FormatDuration(sess.Timeout)
+	}
+	note += ": the run was sent SIGTERM, then SIGKILL if it had not exited after a grace period."
+	if sess.ExitCode == 137 {
+		note += " It had to be killed."
+	}
+	return note
+}
+
 func (m *Model) setContent(sess *backend.Session, events []backend.StreamEvent) {
 	var s string
 
@@ -96,14 +194,150 @@ func (m *Model) setContent(sess *backend.Session, events []backend.StreamEvent)
 		if sess.WorkingDir != "" {
 			s += ui.StyleDim.Render(sess.WorkingDir) + "\n"
 		}
-		s += ui.StyleDim.Render("────────────────────────────────────────") + "\n\n"
+		if sess.Status == "timeout" {
+			s += ui.StyleError.Render(timeoutNote(sess)) + "\n"
+		}
+		s += ui.StyleDim.Render("────────────────────────────────────────") + "\n"
+	}
+	m.header = nil
+	if s != "" {
+		m.header = strings.Split(s, "\n")
 	}
 
-	if len(events) == 0 {
-		s += ui.StyleDim.Render("(no log data)")
-	} else {
-		s += backend.FormatLogEvents(events)
+	m.transcript = backend.NewTranscript(events)
+	m.entries = nil
+	m.events = len(events)
+	if m.selected >= len(m.transcript.Entries) {
+		m.selected = -1
+	}
+	m.refresh()
+}
+
+// refresh renders the entries that are new or stale and updates the
+// viewport content. Lines before the first stale entry are kept, so live
+// tail, which mostly adds entries at the end, only lays out what it added.
+func (m *Model) refresh() {
+	if m.transcript == nil {
+		return
+	}
+	entries := m.transcript.Entries
+	for len(m.entries) < len(entries) {
+		m.entries = append(m.entries, nil)
+	}
+
+	keep := 0
+	for keep < len(m.offsets) && m.entries[keep] != nil {
+		keep++
+	}
+	if keep == 0 {
+		m.lines = append(m.lines[:0], m.header...)
+		if len(entries) == 0 {
+			m.lines = append(m.lines, ui.StyleDim.Render("(no log data)"))
+		}
+	} else if keep < len(m.offsets) {
+		m.lines = m.lines[:m.offsets[keep]]
+	}
+	m.offsets = m.offsets[:keep]
+	for i := keep; i < len(entries); i++ {
+		if m.entries[i] == nil {
+			m.entries[i] = renderEntry(entries[i], m.isOpen(i), i == m.selected)
+		}
+		m.offsets = append(m.offsets, len(m.lines))
+		m.lines = append(m.lines, m.entries[i]...)
+	}
+	m.showLines()
+}
+
+func (m *Model) isOpen(i int) bool {
+	return m.open[i] != m.openAll
+}
+
+// selectTool moves the selection to the next (dir 1) or previous (dir -1)
+// tool block. With nothing selected on screen it starts from the top of
+// the screen going down, or from the bottom going up.
+func (m *Model) selectTool(dir int) {
+	if m.transcript == nil {
+		return
+	}
+	entries := m.transcript.Entries
+	from := m.selected
+	if from < 0 || !m.onScreen(from) {
+		// Start at the screen edge the selection moves away from, just
+		// outside it so the first step can land on the first entry.
+		edge := m.viewport.YOffset()
+		if dir < 0 {
+			edge += m.viewport.Height()
+		}
+		from = len(entries)
+		for i := range entries {
+			if m.offsets[i] >= edge {
+				from = i
+				break
+			}
+		}
+		if dir > 0 {
+			from--
+		}
+	}
+	for i := from + dir; i >= 0 && i < len(entries); i += dir {
+		if entries[i].IsTool() {
+			m.setSelected(i)
+			return
+		}
 	}
+}
+
+func (m *Model) setSelected(i int) {
+	if prev := m.selected; prev >= 0 && prev < len(m.entries) {
+		m.entries[prev] = nil
+	}
+	m.selected = i
+	m.entries[i] = nil
+	m.refresh()
+	m.scrollTo(i)
+}
+
+// toggleSelected opens or closes the selected tool block.
+func (m *Model) toggleSelected() {
+	i := m.selected
+	if i < 0 || m.transcript == nil || i >= len(m.transcript.Entries) {
+		return
+	}
+	m.open[i] = !m.open[i]
+	m.entries[i] = nil
+	m.refresh()
+	m.scrollTo(i)
+}
 
-	m.viewport.SetContent(s)
+// setAllOpen opens or closes every tool block, including ones still to come.
+func (m *Model) setAllOpen(open bool) {
+	m.openAll = open
+	m.open = make(map[int]bool)
+	for i := range m.entries {
+		m.entries[i] = nil
+	}
+	m.refresh()
+	if m.selected >= 0 {
+		m.scrollTo(m.selected)
+	}
+}
+
+// onScreen reports whether the first line of entry i is visible.
+func (m *Model) onScreen(i int) bool {
+	top := m.viewport.YOffset()
+	return m.offsets[i] >= top && m.offsets[i] < top+m.viewport.Height()
+}
+
+// scrollTo scrolls so entry i starts on screen, showing as much of it as fits.
+func (m *Model) scrollTo(i int) {
+	start := m.offsets[i]
+	end := start + len(m.entries[i])
+	top := m.viewport.YOffset()
+	h := m.viewport.Height()
+	switch {
+	case start < top:
+		m.viewport.SetYOffset(start)
+	case end > top+h:
+		m.viewport.SetYOffset(min(start, end-h))
+	}
 }
@@ -0,0 +1,259 @@
+package logview
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/olivoil/workmode/tui/internal/ui"
+)
+
+// search is the state of `/` search in the log view. Matches are found in
+// the rendered lines, so they cover what is shown: the text of closed tool
+// blocks is only searched once they are opened.
+type search struct {
+	input   textinput.Model
+	typing  bool           // the prompt has focus
+	re      *regexp.Regexp // the active pattern; nil for none
+	err     error          // why the typed pattern doesn't compile
+	matches []match
+	current int // index in matches, -1 until one is picked
+}
+
+// match is a search hit on one content line, in terminal cells.
+type match struct {
+	line, start, end int
+}
+
+func newSearch() search {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "regexp (smart case)"
+	ti.CharLimit = 256
+	return search{input: ti, current: -1}
+}
+
+// active reports whether the search bar is shown.
+func (s *search) active() bool {
+	return s.typing || s.re != nil
+}
+
+// compilePattern compiles a search pattern. A pattern without upper case
+// letters matches case-insensitively.
+func compilePattern(p string) (*regexp.Regexp, error) {
+	if !strings.ContainsFunc(p, unicode.IsUpper) {
+		p = "(?i)" + p
+	}
+	return regexp.Compile(p)
+}
+
+// find returns the matches of re in lines, which may contain styling.
+func find(re *regexp.Regexp, lines []string) []match {
+	var matches []match
+	for i, l := range lines {
+		plain := ansi.Strip(l)
+		for _, loc := range re.FindAllStringIndex(plain, -1) {
+			if loc[0] == loc[1] {
+				continue // empty matches can't be shown
+			}
+			matches = append(matches, match{
+				line:  i,
+				start: ansi.StringWidth(plain[:loc[0]]),
+				end:   ansi.StringWidth(plain[:loc[1]]),
+			})
+		}
+	}
+	return matches
+}
+
+// startSearch opens the search prompt.
+func (m *Model) startSearch() tea.Cmd {
+	m.search.typing = true
+	m.search.err = nil
+	m.search.input.SetValue("")
+	m.resize()
+	return m.search.input.Focus()
+}
+
+// ClearSearch removes the search and its highlights. It reports whether
+// there was one, so esc clears a search before it closes the view.
+func (m *Model) ClearSearch() bool {
+	if !m.search.active() {
+		return false
+	}
+	m.search.input.Blur()
+	m.search.typing = false
+	m.search.re = nil
+	m.search.err = nil
+	m.search.matches = nil
+	m.search.current = -1
+	m.resize()
+	m.showLines()
+	return true
+}
+
+// Typing reports whether the search prompt has focus and wants every key.
+func (m *Model) Typing() bool {
+	return m.search.typing
+}
+
+// updateSearch handles a key while the search prompt has focus. The
+// pattern is applied as it is typed.
+func (m *Model) updateSearch(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		if m.search.err != nil {
+			return nil // fix the pattern or esc
+		}
+		m.search.input.Blur()
+		m.search.typing = false
+		if m.search.re == nil {
+			m.ClearSearch()
+		}
+		return nil
+	case "esc":
+		m.ClearSearch()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	p := m.search.input.Value()
+	if p == "" {
+		m.search.re, m.search.err = nil, nil
+		m.search.matches, m.search.current = nil, -1
+		m.showLines()
+		return cmd
+	}
+	re, err := compilePattern(p)
+	if err != nil {
+		m.search.err = err // keep showing the last valid pattern's matches
+		return cmd
+	}
+	m.search.re, m.search.err = re, nil
+	m.search.current = -1
+	m.showLines()
+	m.nextMatch(1)
+	return cmd
+}
+
+// nextMatch moves to the next (dir 1) or previous (dir -1) match, wrapping
+// around. The first move goes to the first match on or below the top of the
+// screen, or the last one above the bottom when going up.
+func (m *Model) nextMatch(dir int) {
+	if len(m.search.matches) == 0 {
+		return
+	}
+	matches := m.search.matches
+	if m.search.current < 0 {
+		top := m.viewport.YOffset()
+		if dir > 0 {
+			m.search.current = 0
+			for i, mt := range matches {
+				if mt.line >= top {
+					m.search.current = i
+					break
+				}
+			}
+		} else {
+			bottom := top + m.viewport.Height()
+			m.search.current = len(matches) - 1
+			for i := len(matches) - 1; i >= 0; i-- {
+				if matches[i].line < bottom {
+					m.search.current = i
+					break
+				}
+			}
+		}
+	} else {
+		m.search.current = (m.search.current + dir + len(matches)) % len(matches)
+	}
+	current := matches[m.search.current]
+	m.showLines()
+
+	line := current.line
+	top, h := m.viewport.YOffset(), m.viewport.Height()
+	if line < top || line >= top+h {
+		m.viewport.SetYOffset(max(0, line-h/2))
+	}
+}
+
+// showLines sets the viewport content to the rendered lines, with the
+// search matches highlighted. Matches are found again each time, since the
+// content grows on live tail and changes as tool blocks open and close.
+func (m *Model) showLines() {
+	s := &m.search
+	if s.re == nil {
+		// Capped so the viewport can't write past the end of m.lines,
+		// which refresh appends to
+		m.viewport.SetContentLines(m.lines[:len(m.lines):len(m.lines)])
+		return
+	}
+
+	// Keep the current match across the update when it is still there.
+	var current match
+	if s.current >= 0 && s.current < len(s.matches) {
+		current = s.matches[s.current]
+	}
+	s.matches = find(s.re, m.lines)
+	s.current = -1
+	for i, mt := range s.matches {
+		if mt == current {
+			s.current = i
+			break
+		}
+	}
+
+	lines := append([]string(nil), m.lines...)
+	byLine := make(map[int][]lipgloss.Range)
+	for i, mt := range s.matches {
+		style := ui.StyleMatch
+		if i == s.current {
+			style = ui.StyleMatchCurrent
+		}
+		byLine[mt.line] = append(byLine[mt.line], lipgloss.NewRange(mt.start, mt.end, style))
+	}
+	for i, ranges := range byLine {
+		lines[i] = lipgloss.StyleRanges(lines[i], ranges...)
+	}
+	m.viewport.SetContentLines(lines)
+}
+
+// searchBar renders the prompt, or the active pattern, with the position
+// of the current match.
+func (m *Model) searchBar() string {
+	s := &m.search
+	var bar string
+	if s.typing {
+		bar = s.input.View()
+	} else {
+		bar = ui.StyleDim.Render("/") + s.input.Value()
+	}
+
+	var count string
+	switch {
+	case s.re == nil:
+	case len(s.matches) == 0:
+		count = ui.StyleError.Render("no matches")
+	case s.current < 0:
+		count = fmt.Sprintf("%d matches", len(s.matches))
+	default:
+		count = fmt.Sprintf("%d/%d", s.current+1, len(s.matches))
+	}
+	if count != "" {
+		bar += "  " + ui.StyleDim.Render("[") + count + ui.StyleDim.Render("]")
+	}
+	if s.err != nil {
+		bar += "  " + ui.StyleError.Render("invalid pattern")
+	}
+	if !s.typing {
+		bar += ui.StyleDim.Render("  n/N next/prev  esc clear")
+	}
+	return bar
+}
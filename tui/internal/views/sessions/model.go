@@ -1,6 +1,9 @@
 package sessions
 
 import (
+	"fmt"
+	"strings"
+
 	tea "charm.land/bubbletea/v2"
 	"charm.land/bubbles/v2/table"
 	"charm.land/bubbles/v2/viewport"
@@ -13,6 +16,21 @@ import (
 const (
 	previewWidthFrac = 0.45
 	minPreviewWidth  = 30
+	maxPreviewFiles  = 8
+	maxPromptLines   = 8
+)
+
+// Table columns, in the order New lays them out.
+const (
+	colStatus = iota
+	colTrigger
+	colTime
+	colDuration
+	colCost
+	colTokens
+	colID
+	colSummary
+	numCols
 )
 
 // Model is the sessions view.
@@ -26,18 +44,22 @@ type Model struct {
 
 	previewID     string
 	previewEvents []backend.StreamEvent
+	previewLog    string // FormatLogEvents(previewEvents), extended on live tail
+	previewFiles  []backend.FileChange
+	previewPrompt string // the prompt the previewed session was sent
 }
 
 // New creates a new sessions view model.
 func New() Model {
-	cols := []table.Column{
-		{Title: " ", Width: 2},
-		{Title: "trigger", Width: 14},
-		{Title: "time", Width: 10},
-		{Title: "dur", Width: 5},
-		{Title: "id", Width: 18},
-		{Title: "summary", Width: 30},
-	}
+	cols := make([]table.Column, numCols)
+	cols[colStatus] = table.Column{Title: " ", Width: 2}
+	cols[colTrigger] = table.Column{Title: "trigger", Width: 14}
+	cols[colTime] = table.Column{Title: "time", Width: 10}
+	cols[colDuration] = table.Column{Title: "dur", Width: 5}
+	cols[colCost] = table.Column{Title: "cost", Width: 6}
+	cols[colTokens] = table.Column{Title: "tok", Width: 6}
+	cols[colID] = table.Column{Title: "id", Width: 18}
+	cols[colSummary] = table.Column{Title: "summary", Width: 30}
 
 	t := table.New(
 		table.WithColumns(cols),
@@ -80,18 +102,14 @@ func (m *Model) RefreshStyles() {
 }
 
 // SetSessions updates the session data and rebuilds the table rows.
+// Earlier attempts of a retried run are listed under its latest attempt,
+// and runs a session chained to or fanned out are listed under it.
 func (m *Model) SetSessions(sessions []backend.Session) {
+	sessions = groupRuns(sessions)
 	m.sessions = sessions
 	rows := make([]table.Row, len(sessions))
-	for i, s := range sessions {
-		rows[i] = table.Row{
-			ui.StatusIcon(s.Status),
-			s.Trigger,
-			ui.FormatTime(s.Started),
-			ui.FormatDuration(s.Duration),
-			s.Short,
-			"",
-		}
+	for i := range sessions {
+		rows[i] = m.row(i, "")
 	}
 	m.table.SetRows(rows)
 	// Show preview metadata for current selection immediately.
@@ -100,10 +118,178 @@ func (m *Model) SetSessions(sessions []backend.Session) {
 	}
 }
 
+// ApplyDiff applies a history change. Updates to listed sessions are
+// patched into their rows, keeping summaries already loaded; new sessions
+// or a reset rebuild the table from all.
+func (m *Model) ApplyDiff(diff backend.SessionDiff, all []backend.Session) {
+	if diff.Reset || len(diff.Added) > 0 {
+		m.SetSessions(all)
+		return
+	}
+	index := make(map[string]int, len(m.sessions))
+	for i, s := range m.sessions {
+		index[s.ID] = i
+	}
+	rows := m.table.Rows()
+	for _, s := range diff.Updated {
+		i, ok := index[s.ID]
+		if !ok || i >= len(rows) || len(rows[i]) != numCols {
+			m.SetSessions(all)
+			return
+		}
+		if !s.HasStats() {
+			s.RunStats = m.sessions[i].RunStats // filled from the log
+		}
+		m.sessions[i] = s
+		rows[i] = m.row(i, rows[i][colSummary])
+	}
+	m.table.SetRows(rows)
+	if s := m.SelectedSession(); s != nil && s.Short == m.previewID {
+		m.preview.SetContent(m.renderPreview(s))
+	}
+}
+
+// maxChainIndent is the deepest chained run indented further.
+const maxChainIndent = 3
+
+// row renders the table row for m.sessions[i].
+func (m *Model) row(i int, summary string) table.Row {
+	s := m.sessions[i]
+	trigger := s.Trigger
+	indent := ""
+	if d := m.depth(s); d > 0 {
+		// Deep chains stop indenting so the trigger name still fits
+		indent = strings.Repeat("  ", min(d, maxChainIndent)-1)
+		trigger = indent + "↳ " + s.Trigger
+	}
+	if summary == "" && s.Fanout > 0 {
+		summary = ui.StyleDim.Render(fmt.Sprintf("fanned out %d item%s", s.Fanout, plural(s.Fanout)))
+	}
+	if i > 0 && m.sessions[i-1].RetryRoot() == s.RetryRoot() {
+		trigger = fmt.Sprintf("%s  └ attempt %d", indent, max(s.Attempt, 1))
+	}
+	row := make(table.Row, numCols)
+	row[colStatus] = ui.StatusIcon(s.Status)
+	row[colTrigger] = trigger
+	row[colTime] = ui.FormatTime(s.Started)
+	row[colDuration] = ui.FormatDuration(s.Duration)
+	row[colCost] = ui.FormatCost(s.CostUSD)
+	row[colTokens] = ui.FormatTokens(s.TotalTokens())
+	row[colID] = s.Short
+	row[colSummary] = summary
+	return row
+}
+
+// depth is how far a run is nested under the run that started it: its
+// place in a chain, or one below its fan-out run.
+func (m *Model) depth(s backend.Session) int {
+	if s.FanoutOf == "" {
+		return s.ChainDepth
+	}
+	for _, f := range m.sessions {
+		if f.ID == s.FanoutOf {
+			return f.ChainDepth + 1
+		}
+	}
+	return 0 // the fan-out run was pruned
+}
+
+// groupRuns reorders sessions (newest first) so all attempts of a retried
+// run are adjacent, at the position of the latest attempt, and a chain of
+// runs is listed in the order it ran, at the position of its newest run:
+// each run's attempts, then the runs it chained to or fanned out.
+func groupRuns(sessions []backend.Session) []backend.Session {
+	groups := make(map[string][]backend.Session)
+	retryRoot := make(map[string]string, len(sessions)) // ID → first attempt
+	var order []string
+	chained := false
+	for _, s := range sessions {
+		root := s.RetryRoot()
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], s)
+		retryRoot[s.ID] = root
+		chained = chained || s.Origin() != ""
+	}
+	if len(order) == len(sessions) && !chained {
+		return sessions
+	}
+
+	// Runs each run started, oldest first; a parent pruned from history
+	// leaves its children at the top level.
+	parent := make(map[string]string)
+	children := make(map[string][]string)
+	for i := len(order) - 1; i >= 0; i-- {
+		root := order[i]
+		if p, ok := retryRoot[groups[root][0].Origin()]; ok && p != root {
+			parent[root] = p
+			children[p] = append(children[p], root)
+		}
+	}
+
+	grouped := make([]backend.Session, 0, len(sessions))
+	done := make(map[string]bool, len(order))
+	var add func(root string)
+	add = func(root string) {
+		if done[root] {
+			return
+		}
+		done[root] = true
+		grouped = append(grouped, groups[root]...)
+		for _, c := range children[root] {
+			add(c)
+		}
+	}
+	for _, root := range order {
+		top := root
+		for seen := map[string]bool{root: true}; ; {
+			p, ok := parent[top]
+			if !ok || done[p] || seen[p] {
+				break
+			}
+			seen[p] = true
+			top = p
+		}
+		add(top)
+	}
+	return grouped
+}
+
+// SetPrompt sets the prompt shown with the next preview: that of the
+// session whose log was just read.
+func (m *Model) SetPrompt(prompt string) {
+	m.previewPrompt = prompt
+}
+
 // SetPreview sets the preview content for a session.
 func (m *Model) SetPreview(shortID string, events []backend.StreamEvent) {
+	m.previewLog = backend.FormatLogEvents(events)
+	m.showPreview(shortID, events)
+	m.preview.GotoTop()
+}
+
+// AppendPreview updates the preview from a log read: events is the whole
+// log and the last added of them are new. If the preview already shows the
+// earlier events, only the new ones are rendered and the scroll position
+// is kept.
+func (m *Model) AppendPreview(shortID string, events []backend.StreamEvent, added int) {
+	shown := len(m.previewEvents)
+	if shortID != m.previewID || shown == 0 || shown != len(events)-added {
+		if shortID == m.previewID && len(events) < shown && added < len(events) {
+			return // an older read arriving late
+		}
+		m.SetPreview(shortID, events)
+		return
+	}
+	m.previewLog += backend.FormatLogEvents(events[shown:])
+	m.showPreview(shortID, events)
+}
+
+func (m *Model) showPreview(shortID string, events []backend.StreamEvent) {
 	m.previewID = shortID
 	m.previewEvents = events
+	m.previewFiles = backend.ExtractFileChanges(events)
 
 	var sess *backend.Session
 	var sessIdx int
@@ -115,19 +301,24 @@ func (m *Model) SetPreview(shortID string, events []backend.StreamEvent) {
 		}
 	}
 
-	// Update summary in the table row if we have log events.
+	// Update summary in the table row if we have log events. Sessions
+	// logged before stats were recorded (or still running) take them from
+	// the log's result event.
 	if len(events) > 0 {
 		summary := backend.ExtractSummary(events, 60)
+		if stats, ok := backend.ExtractRunStats(events); ok && sess != nil && !sess.HasStats() {
+			sess.RunStats = stats
+		}
 		rows := m.table.Rows()
-		if sessIdx < len(rows) && len(rows[sessIdx]) == 6 {
-			rows[sessIdx][5] = summary
+		if sessIdx < len(rows) && len(rows[sessIdx]) == numCols {
+			rows[sessIdx][colCost] = ui.FormatCost(sess.CostUSD)
+			rows[sessIdx][colTokens] = ui.FormatTokens(sess.TotalTokens())
+			rows[sessIdx][colSummary] = summary
 			m.table.SetRows(rows)
 		}
 	}
 
-	content := m.renderPreview(sess, events)
-	m.preview.SetContent(content)
-	m.preview.GotoTop()
+	m.preview.SetContent(m.renderPreview(sess))
 }
 
 // SetSize updates the view dimensions.
@@ -146,14 +337,16 @@ func (m *Model) SetSize(w, h int) {
 	m.preview.SetWidth(previewW)
 	m.preview.SetHeight(h)
 
-	fixedW := 2 + 14 + 10 + 5 + 18 + 5
-	summaryW := tableW - fixedW
-	if summaryW < 10 {
-		summaryW = 10
-	}
 	cols := m.table.Columns()
-	if len(cols) == 6 {
-		cols[5].Width = summaryW
+	if len(cols) == numCols {
+		// The summary takes what the other columns and their padding leave
+		fixedW := 7
+		for i, c := range cols {
+			if i != colSummary {
+				fixedW += c.Width
+			}
+		}
+		cols[colSummary].Width = max(tableW-fixedW, 10)
 		m.table.SetColumns(cols)
 	}
 }
@@ -175,6 +368,42 @@ func (m *Model) SelectedShortID() string {
 	return ""
 }
 
+// shortID returns the short ID of a listed session, or the full ID when it
+// isn't listed (pruned from history).
+func (m *Model) shortID(id string) string {
+	for _, s := range m.sessions {
+		if s.ID == id {
+			return s.Short
+		}
+	}
+	return id
+}
+
+// chainedRuns lists the runs a session chained to, as "short (on)".
+func (m *Model) chainedRuns(id string) []string {
+	var runs []string
+	for _, s := range m.sessions {
+		if s.Parent == id && s.RetryOf == "" {
+			runs = append(runs, s.Short+ui.StyleDim.Render(" ("+s.Chain+")"))
+		}
+	}
+	return runs
+}
+
+// itemRuns returns the latest attempt of each item run a fan-out run
+// queued, in the order they are listed.
+func (m *Model) itemRuns(id string) []backend.Session {
+	var runs []backend.Session
+	seen := make(map[string]bool)
+	for _, s := range m.sessions {
+		if s.FanoutOf == id && !seen[s.RetryRoot()] {
+			seen[s.RetryRoot()] = true
+			runs = append(runs, s)
+		}
+	}
+	return runs
+}
+
 // Focus sets focus on the sessions table.
 func (m *Model) Focus() {
 	m.focused = true
@@ -218,7 +447,7 @@ func (m *Model) previewWidth() int {
 	return pw
 }
 
-func (m *Model) renderPreview(sess *backend.Session, events []backend.StreamEvent) string {
+func (m *Model) renderPreview(sess *backend.Session) string {
 	if sess == nil {
 		return ui.StyleDim.Render("No session selected")
 	}
@@ -228,6 +457,9 @@ func (m *Model) renderPreview(sess *backend.Session, events []backend.StreamEven
 	s += ui.StyleAccent.Render("Session: ") + sess.Short + "\n"
 	s += ui.StyleDim.Render("Full ID: ") + sess.ID + "\n"
 	s += ui.StyleDim.Render("Status:  ") + sess.Status + "\n"
+	if sess.Status == "queued" {
+		s += ui.StyleDim.Render("Priority:") + fmt.Sprintf(" %d", sess.Priority) + "\n"
+	}
 	s += ui.StyleDim.Render("Trigger: ") + sess.Trigger + "\n"
 	if sess.Label != "" && sess.Label != sess.Trigger {
 		s += ui.StyleDim.Render("Label:   ") + sess.Label + "\n"
@@ -237,20 +469,187 @@ func (m *Model) renderPreview(sess *backend.Session, events []backend.StreamEven
 		s += ui.StyleDim.Render("Duration:") + " " + ui.FormatDuration(sess.Duration) + "\n"
 	}
 	s += ui.StyleDim.Render("Dir:     ") + sess.WorkingDir + "\n"
+	if sess.HasGit() {
+		s += ui.StyleDim.Render("Git:     ") + sess.Describe() + "\n"
+		if sess.TreeAfter != "" {
+			s += ui.StyleDim.Render("Diff:    ") + sess.DiffStat()
+			if sess.Commits > 0 {
+				s += fmt.Sprintf(", %d commit%s", sess.Commits, plural(sess.Commits))
+			}
+			var hints []string
+			if sess.FilesChanged > 0 || sess.Commits > 0 {
+				hints = append(hints, "D for diff")
+			}
+			if sess.CanRevert() {
+				hints = append(hints, "u to revert")
+			}
+			if len(hints) > 0 {
+				s += ui.StyleDim.Render(" (" + strings.Join(hints, ", ") + ")")
+			}
+			s += "\n"
+		}
+		if sess.Reverted != "" {
+			s += ui.StyleDim.Render("Reverted:") + " " + ui.FormatTime(sess.Reverted) + "\n"
+		}
+	}
 	if sess.SessionID != "" {
 		s += ui.StyleDim.Render("Claude:  ") + sess.SessionID + "\n"
 	}
+	if sess.Pinned {
+		s += ui.StyleDim.Render("Pinned:  ") + "yes " + ui.StyleDim.Render("(kept by history prune)") + "\n"
+	}
+	if sess.HasStats() {
+		s += ui.StyleDim.Render("Cost:    ") + ui.FormatCost(sess.CostUSD) + "\n"
+		s += ui.StyleDim.Render("Tokens:  ") + fmt.Sprintf("%s in, %s out",
+			formatTokenCount(sess.PromptTokens()), formatTokenCount(sess.OutputTokens))
+		if cached := sess.CacheReadTokens; cached > 0 {
+			s += ui.StyleDim.Render(fmt.Sprintf(" (%s cached)", ui.FormatTokens(cached)))
+		}
+		s += "\n"
+		s += ui.StyleDim.Render("Turns:   ") + fmt.Sprintf("%d", sess.NumTurns)
+		if sess.DurationAPIMs > 0 {
+			s += ui.StyleDim.Render(fmt.Sprintf(" (%s API)", ui.FormatDuration((sess.DurationAPIMs+500)/1000)))
+		}
+		s += "\n"
+		if sess.IsError {
+			s += ui.StyleError.Render("Result:  reported an error") + "\n"
+		}
+	}
+	if files := sess.InputFiles(); len(files) == 1 {
+		s += ui.StyleDim.Render("File:    ") + files[0] + "\n"
+	} else if len(files) > 1 {
+		s += ui.StyleDim.Render("Files:   ") + fmt.Sprintf("%d", len(files)) + "\n"
+		for i, f := range files {
+			if i == maxPreviewFiles {
+				s += ui.StyleDim.Render(fmt.Sprintf("  … and %d more", len(files)-i)) + "\n"
+				break
+			}
+			s += "  " + f + "\n"
+		}
+	}
+	if sess.Attempt > 1 || sess.RetryAt != "" {
+		s += ui.StyleDim.Render("Attempt: ") + formatAttempt(max(sess.Attempt, 1), sess.RetryMax) + "\n"
+	}
 	if sess.Error != "" {
 		s += ui.StyleError.Render("Error:   "+sess.Error) + "\n"
 	}
+	if sess.RetryAt != "" {
+		next := formatAttempt(max(sess.Attempt, 1)+1, sess.RetryMax)
+		s += ui.StyleDim.Render("Retry:   ") + "retry " + next + " at " + ui.FormatTime(sess.RetryAt) + "\n"
+	}
+	if sess.ResumeOf != "" {
+		s += ui.StyleDim.Render("Resumes: ") + m.shortID(sess.ResumeOf) + ui.StyleDim.Render(" (approved tool calls)") + "\n"
+		for _, rule := range sess.AllowedTools {
+			s += "  " + rule + "\n"
+		}
+	}
+	if sess.Parent != "" {
+		s += ui.StyleDim.Render("Chained: ") + "on " + sess.Chain + " of " + m.shortID(sess.Parent) + "\n"
+	}
+	if next := m.chainedRuns(sess.ID); len(next) > 0 {
+		s += ui.StyleDim.Render("Then:    ") + strings.Join(next, ", ") + "\n"
+	}
+	if sess.FanoutOf != "" {
+		s += ui.StyleDim.Render("Item:    ") + sess.ItemKey + ui.StyleDim.Render(" of "+m.shortID(sess.FanoutOf)) + "\n"
+	}
+	if sess.Request != "" {
+		s += ui.StyleDim.Render("Request: ") + sess.Request + "\n"
+	}
+	if sess.Fanout > 0 {
+		s += ui.StyleDim.Render("Fan-out: ") + fmt.Sprintf("%d item run%s", sess.Fanout, plural(sess.Fanout)) + "\n"
+		for i, r := range m.itemRuns(sess.ID) {
+			if i == maxPreviewFiles {
+				s += ui.StyleDim.Render(fmt.Sprintf("  … and %d more", sess.Fanout-i)) + "\n"
+				break
+			}
+			s += "  " + ui.StatusIcon(r.Status) + " " + r.Short + ui.StyleDim.Render("  "+r.ItemKey) + "\n"
+		}
+	}
+	switch {
+	case sess.NeedsApproval():
+		s += ui.StyleDim.Render("Approval:") + " " + lipgloss.NewStyle().Foreground(ui.ColorYellow).Render("pending")
+		if sess.Denials > 0 {
+			s += fmt.Sprintf(", %d tool call%s refused", sess.Denials, plural(sess.Denials))
+		}
+		s += ui.StyleDim.Render(" (a to review)") + "\n"
+	case sess.Approval == "approved":
+		s += ui.StyleDim.Render("Approval:") + " approved, resumed as " + m.shortID(sess.ResumedBy) + "\n"
+	case sess.Approval != "":
+		s += ui.StyleDim.Render("Approval:") + " " + sess.Approval + "\n"
+	}
+
+	if len(m.previewFiles) > 0 {
+		s += ui.StyleDim.Render("Changed: ") + fmt.Sprintf("%d file%s", len(m.previewFiles), plural(len(m.previewFiles)))
+		s += ui.StyleDim.Render(" (c for diffs)") + "\n"
+		for i, f := range m.previewFiles {
+			if i == maxPreviewFiles {
+				s += ui.StyleDim.Render(fmt.Sprintf("  … and %d more", len(m.previewFiles)-i)) + "\n"
+				break
+			}
+			s += "  " + formatFileChange(f) + "\n"
+		}
+	}
+
+	if m.previewPrompt != "" {
+		s += "\n" + ui.StyleDim.Render("─── Prompt ───") + "\n\n"
+		lines := strings.Split(strings.TrimRight(m.previewPrompt, "\n"), "\n")
+		for i, l := range lines {
+			if i == maxPromptLines {
+				s += ui.StyleDim.Render(fmt.Sprintf("… %d more line%s", len(lines)-i, plural(len(lines)-i))) + "\n"
+				break
+			}
+			s += l + "\n"
+		}
+	}
 
 	s += "\n" + ui.StyleDim.Render("─── Log output ───") + "\n\n"
 
-	if len(events) == 0 {
+	if len(m.previewEvents) == 0 {
 		s += ui.StyleDim.Render("(no log data)")
 	} else {
-		s += backend.FormatLogEvents(events)
+		s += m.previewLog
 	}
 
 	return s
 }
+
+// formatFileChange renders a changed file as "M path (2 edits)": W for
+// written, M for edited, ✗ when every change failed.
+func formatFileChange(f backend.FileChange) string {
+	var s string
+	switch f.Op() {
+	case "write":
+		s = ui.StyleDiffAdd.Render("W") + " " + f.Path
+	case "edit":
+		s = ui.StyleAccent.Render("M") + " " + f.Path
+	default:
+		return ui.StyleError.Render("✗ " + f.Path + " (failed)")
+	}
+	if n := f.Applied(); n > 1 {
+		s += ui.StyleDim.Render(fmt.Sprintf(" (%d edits)", n))
+	}
+	return s
+}
+
+// formatAttempt renders "n/max", or just "n" when retries are unlimited.
+func formatAttempt(n, limit int) string {
+	if limit > 0 {
+		return fmt.Sprintf("%d/%d", n, limit)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// formatTokenCount is ui.FormatTokens with "0" instead of blank.
+func formatTokenCount(n int) string {
+	if n <= 0 {
+		return "0"
+	}
+	return ui.FormatTokens(n)
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
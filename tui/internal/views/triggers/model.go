@@ -3,6 +3,7 @@ package triggers
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/bubbles/v2/table"
@@ -20,13 +21,20 @@ const (
 
 // Model is the triggers view.
 type Model struct {
-	table    table.Model
-	preview  viewport.Model
-	triggers []backend.Trigger
-	sessions []backend.Session // all sessions, for showing recent per trigger
-	width    int
-	height   int
-	focused  bool
+	table     table.Model
+	preview   viewport.Model
+	triggers  []backend.Trigger
+	sessions  []backend.Session // all sessions, for showing recent per trigger
+	errors    map[string]string // trigger name → daemon-reported problem
+	budget    backend.Budget    // global spend limits
+	overrides map[string]bool   // triggers with a one-time budget override
+	// When each trigger's scheduler started: the daemon, or its systemd
+	// timer. Interval triggers count from it until they have run.
+	daemonStarted time.Time
+	timerStarts   map[string]time.Time
+	width         int
+	height        int
+	focused       bool
 }
 
 // New creates a new triggers view model.
@@ -35,6 +43,7 @@ func New() Model {
 		{Title: "name", Width: 16},
 		{Title: "type", Width: 6},
 		{Title: "schedule", Width: 24},
+		{Title: "next run", Width: 20},
 		{Title: "permissions", Width: 10},
 		{Title: "label", Width: 20},
 	}
@@ -81,28 +90,114 @@ func (m *Model) RefreshStyles() {
 // SetTriggers updates the trigger data.
 func (m *Model) SetTriggers(triggers []backend.Trigger) {
 	m.triggers = triggers
-	rows := make([]table.Row, len(triggers))
-	for i, t := range triggers {
+	m.refreshRows()
+	m.updatePreview()
+}
+
+// SetSessions stores session data for the recent-sessions preview.
+func (m *Model) SetSessions(sessions []backend.Session) {
+	m.sessions = sessions
+	m.refreshRows()
+	m.updatePreview()
+}
+
+// SetErrors stores per-trigger problems reported by `workmode daemon`.
+func (m *Model) SetErrors(errors map[string]string) {
+	m.errors = errors
+	m.refreshRows()
+	m.updatePreview()
+}
+
+// SetSchedulerStarts stores when `workmode daemon` started (zero when it
+// isn't running) and when each trigger's systemd timer was started.
+func (m *Model) SetSchedulerStarts(daemon time.Time, timers map[string]time.Time) {
+	m.daemonStarted = daemon
+	m.timerStarts = timers
+	m.refreshRows()
+	m.updatePreview()
+}
+
+// SetBudget stores the global spend limits and pending budget overrides.
+func (m *Model) SetBudget(budget backend.Budget, overrides map[string]bool) {
+	m.budget = budget
+	m.overrides = overrides
+	m.refreshRows()
+	m.updatePreview()
+}
+
+// BudgetPause reports whether a trigger is over its own or the global budget.
+func (m *Model) BudgetPause(name string) (backend.BudgetPause, bool) {
+	for _, t := range m.triggers {
+		if t.Name == name {
+			return backend.CheckBudget(m.budget, t, m.sessions, time.Now())
+		}
+	}
+	return backend.BudgetPause{}, false
+}
+
+// RefreshNextRuns recomputes the relative next-run times (called on the
+// status tick so "in 12m" keeps counting down).
+func (m *Model) RefreshNextRuns() {
+	m.refreshRows()
+	m.updatePreview()
+}
+
+func (m *Model) refreshRows() {
+	rows := make([]table.Row, len(m.triggers))
+	for i, t := range m.triggers {
 		label := t.Skill
 		if label == "" && t.Prompt != "" {
 			label = truncate(t.Prompt, 20)
 		}
+		next := ""
+		if _, ok := m.errors[t.Name]; ok {
+			next = "⚠ daemon error"
+		} else if _, paused := m.BudgetPause(t.Name); paused && !m.overrides[t.Name] {
+			next = "⏸ budget-paused"
+		} else if runs := m.nextRuns(t, 1); len(runs) > 0 {
+			next = ui.FormatNextRun(runs[0])
+		} else if t.Type == "timer" && m.schedulerStarted(t).IsZero() {
+			next = "not scheduled"
+		}
 		rows[i] = table.Row{
 			t.Name,
 			t.Type,
 			t.Schedule(),
+			next,
 			t.Permissions,
 			label,
 		}
 	}
 	m.table.SetRows(rows)
-	m.updatePreview()
 }
 
-// SetSessions stores session data for the recent-sessions preview.
-func (m *Model) SetSessions(sessions []backend.Session) {
-	m.sessions = sessions
-	m.updatePreview()
+// schedulerStarted returns when the daemon or the trigger's systemd timer
+// started, or zero when neither is running it.
+func (m *Model) schedulerStarted(t backend.Trigger) time.Time {
+	if !m.daemonStarted.IsZero() {
+		return m.daemonStarted
+	}
+	return m.timerStarts[t.Name]
+}
+
+// nextRuns returns upcoming fire times, or none when nothing schedules the
+// trigger. Interval schedules are anchored on the trigger's latest session
+// or, if later, when its scheduler started.
+func (m *Model) nextRuns(t backend.Trigger, n int) []time.Time {
+	started := m.schedulerStarted(t)
+	if started.IsZero() {
+		return nil
+	}
+	anchor := started
+	for _, s := range m.sessions {
+		if s.Trigger == t.Name {
+			if st := s.StartedTime(); st.After(anchor) {
+				anchor = st
+			}
+			break
+		}
+	}
+	return t.NextRuns(anchor, time.Now(), n)
 }
 
 // SetSize updates the view dimensions.
@@ -174,6 +269,25 @@ func (m *Model) updatePreview() {
 	b.WriteString(ui.StyleAccent.Render("Trigger: ") + trig.Name + "\n")
 	b.WriteString(ui.StyleDim.Render("Type:    ") + trig.Type + "\n")
 	b.WriteString(ui.StyleDim.Render("Schedule:") + " " + trig.Schedule() + "\n")
+	if msg, ok := m.errors[trig.Name]; ok {
+		b.WriteString(ui.StyleError.Render("Error:   "+msg) + "\n")
+	}
+	if p, paused := m.BudgetPause(trig.Name); paused {
+		b.WriteString(ui.StyleError.Render("Paused:  "+p.String()) + "\n")
+		if m.overrides[trig.Name] {
+			b.WriteString(ui.StyleDim.Render("         next run overrides the budget") + "\n")
+		} else {
+			b.WriteString(ui.StyleDim.Render("         b override once · B raise limit") + "\n")
+		}
+	}
+	if runs := m.nextRuns(*trig, 3); len(runs) > 0 {
+		b.WriteString(ui.StyleDim.Render("Next run:") + " " + ui.FormatNextRun(runs[0]) + "\n")
+		for _, r := range runs[1:] {
+			b.WriteString(ui.StyleDim.Render("         ") + " " + r.Local().Format("Mon Jan 02 15:04") + "\n")
+		}
+	} else if trig.Type == "timer" && m.schedulerStarted(*trig).IsZero() {
+		b.WriteString(ui.StyleDim.Render("Next run:") + " not scheduled: start `workmode daemon` or run `workmode install`\n")
+	}
 	if trig.Permissions != "" {
 		b.WriteString(ui.StyleDim.Render("Perms:   ") + trig.Permissions + "\n")
 	}
@@ -192,6 +306,21 @@ func (m *Model) updatePreview() {
 	if trig.Check != "" {
 		b.WriteString(ui.StyleDim.Render("Check:   ") + trig.Check + "\n")
 	}
+	if trig.CheckFormat != "" {
+		fanout := "a run per item (" + trig.CheckFormat + ")"
+		if trig.ItemKey != "" {
+			fanout += ", by " + trig.ItemKey
+		}
+		b.WriteString(ui.StyleDim.Render("Fan-out: ") + fanout + "\n")
+	}
+	concurrency := trig.Concurrency
+	if concurrency == "" {
+		concurrency = "skip"
+	}
+	b.WriteString(ui.StyleDim.Render("Overlap: ") + concurrency + "\n")
+	if trig.Timeout != "" {
+		b.WriteString(ui.StyleDim.Render("Timeout: ") + trig.Timeout + "\n")
+	}
 	if trig.Retry != "" && trig.Retry != "never" {
 		retry := trig.Retry
 		if trig.RetryMax > 0 {
@@ -199,10 +328,25 @@ func (m *Model) updatePreview() {
 			if trig.RetryDelay > 0 {
 				retry += fmt.Sprintf(", delay %ds", trig.RetryDelay)
 			}
+			if trig.RetryBackoff == "exponential" {
+				retry += ", exponential"
+				if trig.RetryDelayMax > 0 {
+					retry += fmt.Sprintf(" ≤%ds", trig.RetryDelayMax)
+				}
+			}
 			retry += ")"
 		}
 		b.WriteString(ui.StyleDim.Render("Retry:   ") + retry + "\n")
 	}
+	for _, next := range [][2]string{{"success", trig.OnSuccess}, {"failure", trig.OnFailure}, {"complete", trig.OnComplete}} {
+		if next[1] != "" {
+			b.WriteString(ui.StyleDim.Render("Then:    ") + next[1] + ui.StyleDim.Render(" on "+next[0]) + "\n")
+		}
+	}
+
+	if spend := m.budgetSummary(*trig); spend != "" {
+		b.WriteString(ui.StyleDim.Render("Spend:   ") + spend + "\n")
+	}
 
 	// Recent sessions for this trigger.
 	b.WriteString("\n" + ui.StyleDim.Render("─── Recent sessions ───") + "\n\n")
@@ -228,6 +372,26 @@ func (m *Model) updatePreview() {
 	m.preview.GotoTop()
 }
 
+// budgetSummary renders the trigger's spend today and this month, with its
+// own limits, e.g. "$1.20/$5.00 today, $14.10 this month".
+func (m *Model) budgetSummary(t backend.Trigger) string {
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	today := backend.Spend(m.sessions, t.Name, day)
+	monthly := backend.Spend(m.sessions, t.Name, month)
+	if monthly == 0 && t.Budget == (backend.Budget{}) {
+		return ""
+	}
+	amount := func(spent, limit float64) string {
+		if limit > 0 {
+			return fmt.Sprintf("$%.2f/$%.2f", spent, limit)
+		}
+		return fmt.Sprintf("$%.2f", spent)
+	}
+	return amount(today, t.Budget.DailyUSD) + " today, " + amount(monthly, t.Budget.MonthlyUSD) + " this month"
+}
+
 func truncate(s string, maxLen int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	if len(s) > maxLen {
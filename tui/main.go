@@ -1,28 +1,310 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/olivoil/workmode/tui/internal/app"
+	"github.com/olivoil/workmode/tui/internal/backend"
+	"github.com/olivoil/workmode/tui/internal/daemon"
 )
 
+// commands are the subcommands workmode-tui runs instead of the TUI.
+var commands = map[string]func(args []string) error{
+	"daemon":  runDaemon,
+	"webhook": runWebhook,
+	"export":  runExport,
+	"denials": runDenials,
+	"items":   runItems,
+	"prompt":  runPrompt,
+}
+
 func main() {
-	for _, arg := range os.Args[1:] {
+	run, args := runTUI, os.Args[1:]
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			run, args = cmd, os.Args[2:]
+		}
+	}
+	if err := run(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return // -h: the flag set printed the usage
+		}
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runTUI starts the interactive UI, or prints the version or usage.
+func runTUI(args []string) error {
+	for _, arg := range args {
 		switch arg {
 		case "--version", "-v", "version":
 			fmt.Printf("%s tui %s\n", app.AppName, app.AppVersion)
-			return
+			return nil
 		case "--help", "-h", "help":
 			fmt.Printf("%s tui %s\n\n", app.AppName, app.AppVersion)
 			fmt.Println("Interactive terminal UI for workmode.")
 			fmt.Println("\nUsage: workmode-tui")
-			return
+			fmt.Println("       workmode-tui daemon    Fire triggers without systemd")
+			fmt.Println("       workmode-tui webhook   Serve webhook triggers (run by systemd with 'workmode on')")
+			fmt.Println("       workmode-tui export [--format md|html|json] [--output path] <session>")
+			fmt.Println("       workmode-tui denials [-0] <session>   Print --allowedTools rules for refused calls")
+			fmt.Println("       workmode-tui prompt [--check] [flags] < template   Render a trigger's prompt template")
+			fmt.Println("       workmode-tui items --format json_lines|json [--key field] --trigger <name> < output")
+			return nil
 		}
 	}
+	return app.Run()
+}
 
-	if err := app.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), "usage: workmode-tui daemon\n\nFire triggers without systemd.") }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: workmode-tui daemon")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	d, err := daemon.New(backend.NewClient(app.CLIBinary, app.AppName))
+	if err != nil {
+		return err
+	}
+	return d.Run(ctx)
+}
+
+// runWebhook serves webhook triggers on their own, for systemd setups
+// where the daemon doesn't run.
+func runWebhook(args []string) error {
+	fs := flag.NewFlagSet("webhook", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), "usage: workmode-tui webhook\n\nServe webhook triggers.") }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: workmode-tui webhook")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return daemon.ServeWebhooks(ctx, backend.NewClient(app.CLIBinary, app.AppName))
+}
+
+// runDenials prints one --allowedTools rule per tool call a session was
+// refused permission for (`workmode session approve`).
+func runDenials(args []string) error {
+	fs := flag.NewFlagSet("denials", flag.ContinueOnError)
+	nul := fs.Bool("0", false, "end each rule with NUL instead of newline (commands may span lines)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: workmode-tui denials [-0] <session>")
+	}
+	args = fs.Args()
+	end := "\n"
+	if *nul {
+		end = "\x00"
+	}
+	client := backend.NewClient(app.CLIBinary, app.AppName)
+	sessions, err := client.ReadSessions()
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s.ID != args[0] && s.Short != args[0] {
+			continue
+		}
+		events, err := client.ReadLog(s.ID)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for _, d := range backend.ExtractDenials(events) {
+			if rule := d.Rule(); !seen[rule] {
+				seen[rule] = true
+				fmt.Print(rule + end)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("session not found: %s", args[0])
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+// runPrompt renders the prompt template on stdin for workmode-run, or with
+// --check only parses it (`workmode config validate`).
+func runPrompt(args []string) error {
+	fs := flag.NewFlagSet("prompt", flag.ContinueOnError)
+	check := fs.Bool("check", false, "parse the template without rendering it")
+	trigger := fs.String("trigger", "", "trigger name")
+	workingDir := fs.String("working-dir", "", "the run's working directory")
+	checkOutput := fs.String("check-output", "", "file holding what the trigger's check command printed")
+	parent := fs.String("parent", "", "session that chained this run")
+	item := fs.String("item", "", "file holding the fan-out item this run is for, as JSON")
+	payload := fs.String("payload", "", "file holding the webhook request body this run is for")
+	var files stringList
+	fs.Var(&files, "file", "input file (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	text, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if *check {
+		_, err := backend.ParsePrompt(string(text))
+		return err
+	}
+
+	client := backend.NewClient(app.CLIBinary, app.AppName)
+	data, err := client.NewPromptData(*trigger, *parent)
+	if err != nil {
+		return err
+	}
+	data.WorkingDir = *workingDir
+	data.Files = files
+	if len(files) > 0 {
+		data.File = files[0]
+	}
+	if *checkOutput != "" {
+		out, err := os.ReadFile(*checkOutput)
+		if err != nil {
+			return err
+		}
+		data.CheckOutput = string(out)
+	}
+	if *item != "" {
+		raw, err := os.ReadFile(*item)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&data.Item); err != nil {
+			return fmt.Errorf("invalid item: %w", err)
+		}
+	}
+	if *payload != "" {
+		body, err := os.ReadFile(*payload)
+		if err != nil {
+			return err
+		}
+		data.Payload = string(body)
+	}
+	out, err := backend.RenderPrompt(string(text), data)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// runItems splits a fan-out trigger's check output into items for
+// workmode-run: "new" or "done" (already processed), the item's key and the
+// item as compact JSON, each ended by NUL.
+func runItems(args []string) error {
+	fs := flag.NewFlagSet("items", flag.ContinueOnError)
+	format := fs.String("format", backend.CheckJSONLines, "json_lines or json")
+	key := fs.String("key", "", "field of each item to deduplicate by")
+	trigger := fs.String("trigger", "", "trigger whose processed items are marked done")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	items, err := backend.ParseItems(data, *format, *key)
+	if err != nil {
+		return err
+	}
+	client := backend.NewClient(app.CLIBinary, app.AppName)
+	sessions, err := client.ReadSessions()
+	if err != nil {
+		return err
+	}
+	done, err := client.PrunedItems(*trigger)
+	if err != nil {
+		return err
+	}
+	for key := range backend.ProcessedItems(sessions, *trigger) {
+		done[key] = true
+	}
+	for _, it := range items {
+		state := "new"
+		if done[it.Key] {
+			state = "done"
+		}
+		done[it.Key] = true // the same item twice in one output runs once
+		fmt.Printf("%s\x00%s\x00%s\x00", state, it.Key, it.Value)
+	}
+	return nil
+}
+
+// runExport writes a session's transcript (`workmode session export`).
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "md, html or json (default: from the output extension, else md)")
+	output := fs.String("output", "", "file to write, - for stdout (default: ./<short id>.<format>)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: workmode-tui export [--format md|html|json] [--output path] <session>")
+	}
+
+	f := backend.ExportFormatFor(*output)
+	if *format != "" {
+		var err error
+		if f, err = backend.ParseExportFormat(*format); err != nil {
+			return err
+		}
+	}
+
+	client := backend.NewClient(app.CLIBinary, app.AppName)
+	sessions, err := client.ReadSessions()
+	if err != nil {
+		return err
+	}
+	id := fs.Arg(0)
+	for _, s := range sessions {
+		if s.ID != id && s.Short != id {
+			continue
+		}
+		path := backend.ExpandHome(*output)
+		if path == "" {
+			path = s.Short + "." + f
+		}
+		if err := client.ExportSession(s, f, path); err != nil {
+			return err
+		}
+		if path != "-" {
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+			fmt.Printf("Exported %s to %s\n", s.Short, path)
+		}
+		return nil
 	}
+	return fmt.Errorf("session not found: %s", id)
 }